@@ -18,8 +18,10 @@ package swapper
 
 import (
 	"context"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	telegram "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -31,7 +33,28 @@ const (
  - Show this help message.
 
 /swap_options
- - Show the settings that you've configured for this Group.
+ - Show the settings that you've configured for this Group, with buttons to quickly change the most common ones.
+
+/swap_history
+ - Show the most recent settings changes made in this Group.
+
+/swap_minimum <keyword length (3 - 16)>
+ - Set the shortest keyword I will swap in this Group.
+
+/swap_maximum <keyword length (3 - 16)>
+ - Set the longest keyword I will swap in this Group.
+
+/swap_silent <true|false|1|0|yes|no>
+ - Determines if I will send swapped Stickers silently (without a notification).
+
+/swap_reply <parent|original>
+ - Set if the swapped Sticker replies to the message the keyword replied to (parent), or to the keyword message itself (original). The original message can only be replied to if it is not deleted.
+
+/swap_timezone <timezone|reset>
+ - Set the timezone (such as "America/New_York") used for the Quiet Hours schedule. Defaults to UTC.
+
+/swap_quiet <HH:MM-HH:MM> [off|limit]
+ - Add a Quiet Hours window where swapping is turned off, or limited to a lower swap Limit. Use "/swap_quiet remove <HH:MM-HH:MM>" to remove a window or "/swap_quiet clear" to remove all of them.
 
 /swap_limit <number of swaps (0 - 65535)>
  - Set a number of times I can perform a swap during the Timeout period. Set to zero to disable.
@@ -43,12 +66,94 @@ const (
  - Determines if I will attempt to delete the swapped message (I can only delete if I have the permissions).
 
 /swap_enable <true|false|1|0|yes|no>
- - Master switch to enable or disable swapping messages in this chat.`
+ - Master switch to enable or disable swapping messages in this chat.
+
+/swap_edits <true|false|1|0|yes|no>
+ - Determines if I will swap messages that are edited to match a swap word.
+
+/swap_captions <true|false|1|0|yes|no>
+ - Determines if I will swap photo and video captions (the media is never deleted).
+
+/swap_animated <true|false|1|0|yes|no>
+ - Determines if I will swap in animated stickers.
+
+/swap_video <true|false|1|0|yes|no>
+ - Determines if I will swap in video stickers.
+
+/swap_allowset <sticker set name or link|reset>
+ - Only allow swaps with stickers from the listed sets. Use "reset" to clear the list.
+
+/swap_blockset <sticker set name or link|reset>
+ - Never swap stickers from the listed sets. Use "reset" to clear the list.
+
+/swap_removeset <sticker set name or link>
+ - Remove a sticker set from the allow or block list.
+
+/swap_blockword <word>
+ - Never swap this word in this chat, for any member.
+
+/swap_unblockword <word>
+ - Remove a word from the blocked words list.
+
+/swap_block [word|sticker] [reason]
+ - Reply to a swap with this to stop that member's word (or that sticker for everyone) from being swapped here. I'll let the member know why.
+
+/swap_unblock [word|sticker]
+ - Reply to a swap with this to remove a block.
+
+/swap_language <code|reset>
+ - Set the language I use in this chat. When reset, I'll use the language of each member.
+
+/swap_attribution <none|message|reply>
+ - Choose if I will post who sent a swap, either as a message or as a reply to the sticker.
+
+/swap_template <text|reset>
+ - Set the attribution message text. You can use {user}, {name}, {keyword} and {mention} in the text.
+
+/swap_autodelete <number of seconds (0 - 65535)>
+ - Delete the attribution message after this many seconds. Set to zero to disable.
+
+Any member can also use "/swap_optout" to stop me from swapping their messages in this chat, and "/swap_optin" to undo that.
+
+In forum groups, these commands can be used inside a topic to override the group settings for that topic only:
+
+/swap_topic_enable <true|false|1|0|yes|no>
+/swap_topic_limit <number of swaps (0 - 65535)>
+/swap_topic_timeout <number of seconds (0 - 65535)>
+/swap_topic_reset
+ - Remove the overrides for this topic.`
 	errorMessageAdmin = `Sorry I've seem to have encountered an error when changing that setting.
 
 Please try again later.`
 )
 
+var permissions = map[string]func(*telegram.ChatMember) bool{
+	"can_manage_chat":      func(m *telegram.ChatMember) bool { return m.CanManageChat },
+	"can_change_info":      func(m *telegram.ChatMember) bool { return m.CanChangeInfo },
+	"can_invite_users":     func(m *telegram.ChatMember) bool { return m.CanInviteUsers },
+	"can_pin_messages":     func(m *telegram.ChatMember) bool { return m.CanPinMessages },
+	"can_promote_members":  func(m *telegram.ChatMember) bool { return m.CanPromoteMembers },
+	"can_delete_messages":  func(m *telegram.ChatMember) bool { return m.CanDeleteMessages },
+	"can_restrict_members": func(m *telegram.ChatMember) bool { return m.CanRestrictMembers },
+}
+
+func (r rights) missing(c string, m *telegram.ChatMember) []string {
+	if m != nil && m.IsCreator() {
+		return nil
+	}
+	v, ok := r[c]
+	if !ok {
+		v = r["*"]
+	}
+	var n []string
+	for i := range v {
+		// Anonymous Admins (nil) can't be checked, so they are missing every right.
+		if m == nil || !permissions[v[i]](m) {
+			n = append(n, v[i])
+		}
+	}
+	return n
+}
 func stringMatchIndex(l int, s, m string) bool {
 	if len(s) < l || len(s) < len(m) {
 		return false
@@ -64,53 +169,345 @@ func stringMatchIndex(l int, s, m string) bool {
 	}
 	return true
 }
+func validSetName(v string) bool {
+	if len(v) == 0 || len(v) > 64 {
+		return false
+	}
+	for i := range v {
+		if (v[i] < 'a' || v[i] > 'z') && (v[i] < '0' || v[i] > '9') && v[i] != '_' {
+			return false
+		}
+	}
+	return true
+}
 func sendResponse(o chan<- telegram.Chattable, i int64, r int, s string) {
 	n := telegram.NewMessage(i, s)
 	n.ReplyToMessageID = r
 	o <- n
 }
-func (c *container) config(x context.Context, s *Swapper, m *telegram.Message, o chan<- telegram.Chattable) {
-	u, err := c.bot.GetChatMember(telegram.GetChatMemberConfig{
-		ChatConfigWithUser: telegram.ChatConfigWithUser{ChatID: m.Chat.ID, UserID: m.From.ID},
-	})
+func parseBool(v string) (bool, bool) {
+	switch v {
+	case "1", "true", "t", "yes":
+		return true, true
+	case "0", "false", "f", "no":
+		return false, true
+	}
+	return false, false
+}
+func (s *Swapper) options(n string, g settings, i int64, t int) string {
+	v := s.lang.get(n, "options",
+		"{enabled}", strconv.FormatBool(g.enabled), "{remove}", strconv.FormatBool(g.remove),
+		"{limit}", strconv.Itoa(int(g.amount)), "{timeout}", strconv.Itoa(int(g.timeout)),
+		"{attribution}", attributions[g.attribution], "{expire}", strconv.Itoa(int(g.expire)),
+		"{edits}", strconv.FormatBool(g.edits), "{captions}", strconv.FormatBool(g.captions), "{language}", n,
+		"{animated}", strconv.FormatBool(g.animated), "{video}", strconv.FormatBool(g.video),
+		"{minimum}", strconv.Itoa(int(g.minimum)), "{maximum}", strconv.Itoa(int(g.maximum)),
+		"{silent}", strconv.FormatBool(g.silent), "{reply}", replies[g.reply],
+	)
+	if len(g.template) > 0 {
+		v += s.lang.get(n, "options_template", "{template}", g.template)
+	}
+	if len(g.sets) > 0 {
+		var a, b []string
+		for k, e := range g.sets {
+			if e {
+				a = append(a, k)
+			} else {
+				b = append(b, k)
+			}
+		}
+		if sort.Strings(a); len(a) > 0 {
+			v += s.lang.get(n, "options_allowsets", "{sets}", strings.Join(a, ", "))
+		}
+		if sort.Strings(b); len(b) > 0 {
+			v += s.lang.get(n, "options_blocksets", "{sets}", strings.Join(b, ", "))
+		}
+	}
+	if len(g.words) > 0 {
+		w := make([]string, 0, len(g.words))
+		for k := range g.words {
+			w = append(w, k)
+		}
+		sort.Strings(w)
+		v += s.lang.get(n, "options_words", "{words}", strings.Join(w, ", "))
+	}
+	if len(g.schedule) > 0 {
+		z := g.zone
+		if len(z) == 0 {
+			z = "UTC"
+		}
+		v += s.lang.get(n, "options_schedule", "{timezone}", z, "{schedule}", s.windowList(n, g.schedule))
+		if w, ok := s.quiet(i); ok {
+			v += s.lang.get(n, "options_schedule_active", "{window}", s.windowList(n, []window{w}))
+		}
+	}
+	if k, ok := g.topics[t]; ok && t != 0 {
+		e, a, d, _ := g.topic(i, t)
+		v += s.lang.get(n, "options_topic")
+		if k.enabled.Valid {
+			v += s.lang.get(n, "options_topic_on", "{enabled}", strconv.FormatBool(e))
+		}
+		if k.amount.Valid {
+			v += s.lang.get(n, "options_topic_max", "{limit}", strconv.Itoa(int(a)))
+		}
+		if k.timeout.Valid {
+			v += s.lang.get(n, "options_topic_time", "{timeout}", strconv.Itoa(int(d)))
+		}
+	}
+	return v
+}
+func (c *container) quiet(x context.Context, s *Swapper, m *telegram.Message, g settings, n string, f []string, o chan<- telegram.Chattable) {
+	if len(f) == 1 && f[0] == "clear" {
+		if _, err := s.sql.ExecContext(x, "clear_schedule", m.Chat.ID); err != nil {
+			s.logger(x).Error("Received an error when attempting to clear the schedule (GID: %d): %s!", m.Chat.ID, err.Error())
+			sendResponse(o, m.Chat.ID, m.MessageID, c.text(s, n, "error_admin"))
+			return
+		}
+		s.invalidate(m.Chat.ID)
+		s.schedule(x)
+		s.audit(x, m, "swap_quiet", s.windowList(n, g.schedule), "")
+		s.logger(x).Trace(`Admin "%s" cleared the schedule for GID %d!`, m.From.String(), m.Chat.ID)
+		sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "quiet_cleared"))
+		return
+	}
+	var (
+		r = len(f) == 2 && f[0] == "remove"
+		w window
+		k bool
+	)
+	if r {
+		w, k = parseWindow(f[1])
+	} else if len(f) == 1 || len(f) == 2 {
+		w, k = parseWindow(f[0])
+	}
+	if !k {
+		sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "usage", "{usage}", "/swap_quiet <HH:MM-HH:MM> [off|limit] | remove <HH:MM-HH:MM> | clear"))
+		return
+	}
+	if r {
+		if _, err := s.sql.ExecContext(x, "del_schedule", m.Chat.ID, w.start, w.end); err != nil {
+			s.logger(x).Error("Received an error when attempting to remove a schedule (GID: %d): %s!", m.Chat.ID, err.Error())
+			sendResponse(o, m.Chat.ID, m.MessageID, c.text(s, n, "error_admin"))
+			return
+		}
+		s.invalidate(m.Chat.ID)
+		s.schedule(x)
+		s.audit(x, m, "swap_quiet", w.String(), "")
+		s.logger(x).Trace(`Admin "%s" removed the schedule %s for GID %d!`, m.From.String(), w.String(), m.Chat.ID)
+		sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "quiet_removed", "{window}", w.String()))
+		return
+	}
+	if len(f) == 2 && f[1] != "off" {
+		v, err := strconv.ParseUint(f[1], 10, 16)
+		if err != nil || v == 0 {
+			sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "usage", "{usage}", "/swap_quiet <HH:MM-HH:MM> [off|limit] | remove <HH:MM-HH:MM> | clear"))
+			return
+		}
+		w.amount.Int32, w.amount.Valid = int32(v), true
+	}
+	var a any
+	if w.amount.Valid {
+		a = w.amount.Int32
+	}
+	if _, err := s.sql.ExecContext(x, "add_schedule", m.Chat.ID, w.start, w.end, a); err != nil {
+		s.logger(x).Error("Received an error when attempting to add a schedule (GID: %d): %s!", m.Chat.ID, err.Error())
+		sendResponse(o, m.Chat.ID, m.MessageID, c.text(s, n, "error_admin"))
+		return
+	}
+	s.invalidate(m.Chat.ID)
+	s.schedule(x)
+	v := s.windowList(n, []window{w})
+	s.audit(x, m, "swap_quiet", "", v)
+	s.logger(x).Trace(`Admin "%s" added the schedule %s for GID %d!`, m.From.String(), v, m.Chat.ID)
+	sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "quiet_added", "{window}", v))
+}
+func (s *Swapper) windowList(n string, w []window) string {
+	v := make([]string, len(w))
+	for i := range w {
+		if w[i].amount.Valid {
+			v[i] = s.lang.get(n, "schedule_limit", "{window}", w[i].String(), "{limit}", strconv.Itoa(int(w[i].amount.Int32)))
+		} else {
+			v[i] = s.lang.get(n, "schedule_off", "{window}", w[i].String())
+		}
+	}
+	return strings.Join(v, ", ")
+}
+func (s *Swapper) audit(x context.Context, m *telegram.Message, k, o, v string) {
+	n := m.From.String()
+	if m.SenderChat != nil && m.SenderChat.ID == m.Chat.ID {
+		n = "Anonymous Admin"
+	}
+	s.change(x, m.Chat.ID, m.From.ID, n, k, o, v)
+}
+func (s *Swapper) change(x context.Context, i, u int64, n, k, o, v string) {
+	if _, err := s.sql.ExecContext(x, "add_audit", i, u, n, k, o, v); err != nil {
+		s.logger(x).Error("Received an error when attempting to record a settings change (GID: %d): %s!", i, err.Error())
+	}
+}
+func (s *Swapper) history(x context.Context, i int64, n string) string {
+	r, err := s.sql.QueryContext(x, "list_audit", i)
 	if err != nil {
-		s.log.Error("Received an error during ChatMember lookup (GID: %d, UID: %d): %s!", m.Chat.ID, m.From.ID, err.Error())
+		s.logger(x).Error("Received an error when attempting to get the settings history (GID: %d): %s!", i, err.Error())
+		return s.lang.get(n, "error_admin")
+	}
+	var (
+		b             = builders.Get().(*strings.Builder)
+		c             int
+		u, k, o, v, d string
+	)
+	for b.WriteString(s.lang.get(n, "history_header")); r.Next(); c++ {
+		if err = r.Scan(&u, &k, &o, &v, &d); err != nil {
+			break
+		}
+		b.WriteString(s.lang.get(n, "history_entry", "{time}", d, "{name}", u, "{setting}", k, "{old}", o, "{new}", v))
+	}
+	if r.Close(); err != nil {
+		s.logger(x).Error("Received an error when attempting to scan the settings history (GID: %d): %s!", i, err.Error())
+		b.Reset()
+		builders.Put(b)
+		return s.lang.get(n, "error_admin")
+	}
+	e := b.String()
+	b.Reset()
+	if builders.Put(b); c == 0 {
+		return s.lang.get(n, "history_empty")
+	}
+	return e
+}
+func (c *container) moderate(x context.Context, s *Swapper, m *telegram.Message, n string, f []string, o chan<- telegram.Chattable) {
+	if m.ReplyToMessage == nil || m.ReplyToMessage.From == nil || m.ReplyToMessage.From.ID != c.bot.Self.ID {
+		sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "block_reply"))
+		return
+	}
+	e, a := "word", f[1:]
+	if len(a) > 0 && (strings.EqualFold(a[0], "word") || strings.EqualFold(a[0], "sticker")) {
+		e, a = strings.ToLower(a[0]), a[1:]
+	}
+	r, err := s.sql.QueryContext(x, "get_swap_log", m.Chat.ID, m.ReplyToMessage.MessageID)
+	if err != nil {
+		s.logger(x).Error("Received an error when attempting to get a swap record (GID: %d): %s!", m.Chat.ID, err.Error())
+		sendResponse(o, m.Chat.ID, m.MessageID, c.text(s, n, "error_admin"))
 		return
 	}
-	if u.Status != "administrator" && u.Status != "creator" {
-		s.log.Debug(`Non-admin user "%s" attempted an Admin command in GID %d!`, m.From.String(), m.Chat.ID)
+	var (
+		u    int64
+		k, i string
+	)
+	for r.Next() {
+		if err = r.Scan(&u, &k, &i); err != nil {
+			break
+		}
+	}
+	if r.Close(); err != nil {
+		s.logger(x).Error("Received an error when attempting to scan a swap record (GID: %d): %s!", m.Chat.ID, err.Error())
+		sendResponse(o, m.Chat.ID, m.MessageID, c.text(s, n, "error_admin"))
 		return
 	}
-	l := strings.ToLower(strings.TrimSpace(m.Text[1:]))
-	if l == "swap_help" {
-		sendResponse(o, m.Chat.ID, m.MessageID, helpMessageAdmin)
+	if u == 0 || (e == "sticker" && len(i) == 0) {
+		sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "block_unknown"))
 		return
 	}
-	if l == "swap_options" {
-		r, err := s.sql.QueryContext(x, "list_opt", m.Chat.ID)
+	b, q := block{user: u, value: strings.ToLower(k)}, "add_block"
+	if e == "sticker" {
+		b = block{value: i}
+	}
+	if strings.EqualFold(f[0], "swap_unblock") {
+		q = "del_block"
+	}
+	if _, err = s.sql.ExecContext(x, q, m.Chat.ID, b.user, b.value); err != nil {
+		s.logger(x).Error("Received an error when attempting to update a swap block (GID: %d): %s!", m.Chat.ID, err.Error())
+		sendResponse(o, m.Chat.ID, m.MessageID, c.text(s, n, "error_admin"))
+		return
+	}
+	s.invalidate(m.Chat.ID)
+	s.audit(x, m, strings.ToLower(f[0]), "", e+" "+k+" ("+strconv.FormatInt(u, 10)+")")
+	s.logger(x).Trace(`Admin "%s" ran "%s" on the %s of UID %d ("%s") in GID %d!`, m.From.String(), strings.ToLower(f[0]), e, u, k, m.Chat.ID)
+	if q == "del_block" {
+		sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "unblock_"+e, "{word}", k))
+		return
+	}
+	sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "block_"+e, "{word}", k))
+	v := s.lang.get(s.language(x, &telegram.User{ID: u}), "block_notice_"+e, "{word}", k, "{chat}", m.Chat.Title)
+	if len(a) > 0 {
+		v += s.lang.get(s.language(x, &telegram.User{ID: u}), "block_reason", "{reason}", strings.Join(a, " "))
+	}
+	if _, err = c.bot.Send(telegram.NewMessage(u, v)); err != nil {
+		s.logger(x).Debug("Received an error attempting to notify UID %d of a swap block: %s", u, err.Error())
+	}
+}
+func (c *container) config(x context.Context, s *Swapper, m *telegram.Message, t int, o chan<- telegram.Chattable) {
+	if v := strings.ToLower(strings.TrimSpace(m.Text[1:])); v == "swap_optout" || v == "swap_optin" {
+		if m.SenderChat != nil && m.SenderChat.ID == m.Chat.ID {
+			sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(s.language(x, m.From), "optout_anonymous"))
+			return
+		}
+		c.optout(x, s, m, v == "swap_optout", o)
+		return
+	}
+	var a *telegram.ChatMember
+	if m.SenderChat == nil || m.SenderChat.ID != m.Chat.ID {
+		u, err := c.admin(x, s, m.Chat.ID, m.From.ID)
 		if err != nil {
-			s.log.Error("Received an error when attempting to get group settings (GID: %d): %s!", m.Chat.ID, err.Error())
-			sendResponse(o, m.Chat.ID, m.MessageID, errorMessageAdmin)
+			s.logger(x).Error("Received an error during ChatMember lookup (GID: %d, UID: %d): %s!", m.Chat.ID, m.From.ID, err.Error())
+			sendResponse(o, m.Chat.ID, m.MessageID, c.text(s, s.language(x, m.From), "error_admin"))
 			return
 		}
-		var (
-			e, b bool
-			a, t int
-		)
-		for r.Next() {
-			if err = r.Scan(&e, &a, &t, &b); err != nil {
-				break
-			}
+		if u.Status != "administrator" && u.Status != "creator" {
+			s.logger(x).Debug(`Non-admin user "%s" attempted an Admin command in GID %d!`, m.From.String(), m.Chat.ID)
+			sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(s.language(x, m.From), "admin_only"))
+			return
 		}
-		if r.Close(); err != nil {
-			s.log.Error("Received an error when attempting to scan group settings (GID: %d): %s!", m.Chat.ID, err.Error())
-			sendResponse(o, m.Chat.ID, m.MessageID, errorMessageAdmin)
+		a = &u
+	}
+	if f := strings.Fields(m.Text[1:]); len(f) > 0 {
+		if r := s.rights.missing(strings.ToLower(f[0]), a); len(r) > 0 {
+			s.logger(x).Debug(`Admin "%s" is missing the rights %v for "%s" in GID %d!`, m.From.String(), r, f[0], m.Chat.ID)
+			sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(s.language(x, m.From), "admin_rights", "{rights}", strings.Join(r, ", ")))
 			return
 		}
-		sendResponse(o, m.Chat.ID, m.MessageID,
-			"I have the following settings:\n\nSwapping Enabled: "+strconv.FormatBool(e)+"\nRemove Swapped: "+
-				strconv.FormatBool(b)+"\nSwap Limit: "+strconv.Itoa(a)+"\nSwap Timeout: "+strconv.Itoa(t)+" seconds.",
-		)
+	}
+	g, err := s.group(x, m.Chat.ID)
+	if err != nil {
+		s.logger(x).Error("Received an error when attempting to get group settings (GID: %d): %s!", m.Chat.ID, err.Error())
+		sendResponse(o, m.Chat.ID, m.MessageID, c.text(s, s.language(x, m.From), "error_admin"))
+		return
+	}
+	var (
+		n = s.groupLanguage(x, g, m.From)
+		l = strings.ToLower(strings.TrimSpace(m.Text[1:]))
+	)
+	if f := strings.Fields(m.Text[1:]); strings.EqualFold(f[0], "swap_block") || strings.EqualFold(f[0], "swap_unblock") {
+		c.moderate(x, s, m, n, f, o)
+		return
+	}
+	if l == "swap_help" {
+		sendResponse(o, m.Chat.ID, m.MessageID, c.text(s, n, "help_admin"))
+		return
+	}
+	if l == "swap_history" {
+		sendResponse(o, m.Chat.ID, m.MessageID, s.history(x, m.Chat.ID, n))
+		return
+	}
+	if l == "swap_options" {
+		r := telegram.NewMessage(m.Chat.ID, s.options(n, g, m.Chat.ID, t))
+		r.ReplyToMessageID, r.ReplyMarkup = m.MessageID, s.panel(n, g, t)
+		o <- r
+		return
+	}
+	if l == "swap_topic_reset" {
+		if t == 0 {
+			sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "topic_only"))
+			return
+		}
+		if _, err := s.sql.ExecContext(x, "del_topic", m.Chat.ID, t); err != nil {
+			s.logger(x).Error("Received an error when attempting to reset topic settings (GID: %d, topic: %d): %s!", m.Chat.ID, t, err.Error())
+			sendResponse(o, m.Chat.ID, m.MessageID, c.text(s, n, "error_admin"))
+			return
+		}
+		s.invalidate(m.Chat.ID)
+		s.audit(x, m, "swap_topic_reset #"+strconv.Itoa(t), "", "")
+		s.logger(x).Trace(`Admin "%s" reset the topic %d settings for GID %d!`, m.From.String(), t, m.Chat.ID)
+		sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "topic_reset"))
 		return
 	}
 	d := strings.IndexByte(l, ' ')
@@ -121,68 +518,312 @@ func (c *container) config(x context.Context, s *Swapper, m *telegram.Message, o
 	case "limit":
 		v, err := strconv.ParseUint(l[d+1:], 10, 16)
 		if err != nil || v > 65536 {
-			sendResponse(o, m.Chat.ID, m.MessageID, "Sorry I don't recognize that option value.\n\nThe correct usage should be \"/swap-limit <number of swaps (0 - 65535)>\"")
+			sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "usage", "{usage}", "/swap_limit <number of swaps (0 - 65535)>"))
 			return
 		}
 		if _, err := s.sql.ExecContext(x, "set_opt_limit", m.Chat.ID, v); err != nil {
-			s.log.Error("Received an error when attempting to set the limit setting (GID: %d): %s!", m.Chat.ID, err.Error())
-			sendResponse(o, m.Chat.ID, m.MessageID, errorMessageAdmin)
+			s.logger(x).Error("Received an error when attempting to set the limit setting (GID: %d): %s!", m.Chat.ID, err.Error())
+			sendResponse(o, m.Chat.ID, m.MessageID, c.text(s, n, "error_admin"))
 			return
 		}
-		s.log.Trace(`Admin "%s" set the "swap_limit" to "%s" setting for GID %d!`, m.From.String(), l[d+1:], m.Chat.ID)
-		delete(s.limits, m.Chat.ID)
-		sendResponse(o, m.Chat.ID, m.MessageID, `Awesome! I've updated the "swap_limit" setting to `+l[d+1:]+` swaps!`)
+		s.invalidate(m.Chat.ID)
+		s.audit(x, m, "swap_limit", g.value("swap_limit", t), l[d+1:])
+		s.logger(x).Trace(`Admin "%s" set the "swap_limit" to "%s" setting for GID %d!`, m.From.String(), l[d+1:], m.Chat.ID)
+		sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "updated_swaps", "{option}", "swap_limit", "{value}", l[d+1:]))
 		return
 	case "enable":
-		var e bool
-		switch l[d+1:] {
-		case "1", "true", "t", "yes":
-			e = true
-		case "0", "false", "f", "no":
-		default:
-			sendResponse(o, m.Chat.ID, m.MessageID, "Sorry I don't recognize that option value.\n\nThe correct usage should be \"/swap-enabled <true|false|1|0|yes|no>\"")
+		e, ok := parseBool(l[d+1:])
+		if !ok {
+			sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "usage", "{usage}", "/swap_enable <true|false|1|0|yes|no>"))
 			return
 		}
 		if _, err := s.sql.ExecContext(x, "set_opt_enable", m.Chat.ID, e); err != nil {
-			s.log.Error("Received an error when attempting to set enable setting (GID: %d): %s!", m.Chat.ID, err.Error())
-			sendResponse(o, m.Chat.ID, m.MessageID, errorMessageAdmin)
+			s.logger(x).Error("Received an error when attempting to set enable setting (GID: %d): %s!", m.Chat.ID, err.Error())
+			sendResponse(o, m.Chat.ID, m.MessageID, c.text(s, n, "error_admin"))
 			return
 		}
-		s.log.Trace(`Admin "%s" set the "swap_enable" to %t setting for GID %d!`, m.From.String(), e, m.Chat.ID)
-		sendResponse(o, m.Chat.ID, m.MessageID, `Sweet! I've updated the "swap_enable" setting to "`+strconv.FormatBool(e)+`"!`)
+		s.invalidate(m.Chat.ID)
+		s.audit(x, m, "swap_enable", g.value("swap_enable", t), strconv.FormatBool(e))
+		s.logger(x).Trace(`Admin "%s" set the "swap_enable" to %t setting for GID %d!`, m.From.String(), e, m.Chat.ID)
+		sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "updated", "{option}", "swap_enable", "{value}", strconv.FormatBool(e)))
 		return
 	case "delete":
-		var e bool
-		switch l[d+1:] {
-		case "1", "true", "t", "yes":
-			e = true
-		case "0", "false", "f", "no":
-		default:
-			sendResponse(o, m.Chat.ID, m.MessageID, "Sorry I don't recognize that option value.\n\nThe correct usage should be \"/swap-delete <true|false|1|0|yes|no>\"")
+		e, ok := parseBool(l[d+1:])
+		if !ok {
+			sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "usage", "{usage}", "/swap_delete <true|false|1|0|yes|no>"))
 			return
 		}
 		if _, err := s.sql.ExecContext(x, "set_opt_delete", m.Chat.ID, e); err != nil {
-			s.log.Error("Received an error when attempting to set the delete setting (GID: %d): %s!", m.Chat.ID, err.Error())
-			sendResponse(o, m.Chat.ID, m.MessageID, errorMessageAdmin)
+			s.logger(x).Error("Received an error when attempting to set the delete setting (GID: %d): %s!", m.Chat.ID, err.Error())
+			sendResponse(o, m.Chat.ID, m.MessageID, c.text(s, n, "error_admin"))
+			return
+		}
+		s.invalidate(m.Chat.ID)
+		s.audit(x, m, "swap_delete", g.value("swap_delete", t), strconv.FormatBool(e))
+		s.logger(x).Trace(`Admin "%s" set the "swap_delete" to %t setting for GID %d!`, m.From.String(), e, m.Chat.ID)
+		sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "updated", "{option}", "swap_delete", "{value}", strconv.FormatBool(e)))
+		return
+	case "edits", "captions", "animated", "video", "silent":
+		e, ok := parseBool(l[d+1:])
+		if !ok {
+			sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "usage", "{usage}", "/"+l[:d]+" <true|false|1|0|yes|no>"))
+			return
+		}
+		if _, err := s.sql.ExecContext(x, "set_opt_"+l[5:d], m.Chat.ID, e); err != nil {
+			s.logger(x).Error("Received an error when attempting to set the %s setting (GID: %d): %s!", l[5:d], m.Chat.ID, err.Error())
+			sendResponse(o, m.Chat.ID, m.MessageID, c.text(s, n, "error_admin"))
+			return
+		}
+		s.invalidate(m.Chat.ID)
+		s.audit(x, m, l[:d], g.value(l[:d], t), strconv.FormatBool(e))
+		s.logger(x).Trace(`Admin "%s" set the "%s" to %t setting for GID %d!`, m.From.String(), l[:d], e, m.Chat.ID)
+		sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "updated", "{option}", l[:d], "{value}", strconv.FormatBool(e)))
+		return
+	case "blockword", "unblockword":
+		v := strings.TrimSpace(l[d+1:])
+		if len(v) < keywordMin || len(v) > keywordMax {
+			sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "word_length"))
+			return
+		}
+		q := "add_word"
+		if l[5:d] == "unblockword" {
+			q = "del_word"
+		}
+		if _, err := s.sql.ExecContext(x, q, m.Chat.ID, v); err != nil {
+			s.logger(x).Error("Received an error when attempting to set the %s setting (GID: %d): %s!", l[5:d], m.Chat.ID, err.Error())
+			sendResponse(o, m.Chat.ID, m.MessageID, c.text(s, n, "error_admin"))
+			return
+		}
+		s.invalidate(m.Chat.ID)
+		s.audit(x, m, l[:d], "", v)
+		s.logger(x).Trace(`Admin "%s" set the "%s" to "%s" setting for GID %d!`, m.From.String(), l[:d], v, m.Chat.ID)
+		sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "words_"+l[5:d], "{word}", v))
+		return
+	case "allowset", "blockset", "removeset":
+		v := strings.TrimPrefix(strings.TrimPrefix(strings.TrimPrefix(l[d+1:], "https://"), "t.me/"), "addstickers/")
+		if !validSetName(v) {
+			sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "usage", "{usage}", "/"+l[:d]+" <sticker set name or link|reset>"))
+			return
+		}
+		var err error
+		switch {
+		case l[5:d] == "removeset":
+			_, err = s.sql.ExecContext(x, "del_set", m.Chat.ID, v)
+		case v == "reset":
+			_, err = s.sql.ExecContext(x, "clear_sets", m.Chat.ID, l[5:d] == "allowset")
+		default:
+			_, err = s.sql.ExecContext(x, "add_set", m.Chat.ID, v, l[5:d] == "allowset")
+		}
+		if err != nil {
+			s.logger(x).Error("Received an error when attempting to set the %s setting (GID: %d): %s!", l[5:d], m.Chat.ID, err.Error())
+			sendResponse(o, m.Chat.ID, m.MessageID, c.text(s, n, "error_admin"))
+			return
+		}
+		s.invalidate(m.Chat.ID)
+		s.audit(x, m, l[:d], "", v)
+		s.logger(x).Trace(`Admin "%s" set the "%s" to "%s" setting for GID %d!`, m.From.String(), l[:d], v, m.Chat.ID)
+		sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "sets_"+l[5:d], "{set}", v))
+		return
+	case "timezone":
+		var (
+			r = strings.TrimSpace(m.Text[1:])
+			v any
+		)
+		if r = strings.TrimSpace(r[strings.IndexByte(r, ' ')+1:]); l[d+1:] != "reset" {
+			if _, err := time.LoadLocation(r); err != nil || len(r) > 64 || strings.EqualFold(r, "Local") {
+				sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "timezone_invalid", "{timezone}", r))
+				return
+			}
+			v = r
+		}
+		if _, err := s.sql.ExecContext(x, "set_opt_timezone", m.Chat.ID, v); err != nil {
+			s.logger(x).Error("Received an error when attempting to set the timezone setting (GID: %d): %s!", m.Chat.ID, err.Error())
+			sendResponse(o, m.Chat.ID, m.MessageID, c.text(s, n, "error_admin"))
+			return
+		}
+		s.invalidate(m.Chat.ID)
+		s.schedule(x)
+		s.audit(x, m, "swap_timezone", g.value("swap_timezone", t), r)
+		s.logger(x).Trace(`Admin "%s" set the "swap_timezone" to "%s" setting for GID %d!`, m.From.String(), r, m.Chat.ID)
+		sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "updated", "{option}", "swap_timezone", "{value}", r))
+		return
+	case "quiet":
+		c.quiet(x, s, m, g, n, strings.Fields(l[d+1:]), o)
+		return
+	case "language":
+		var v any
+		if l[d+1:] != "reset" {
+			if _, ok := s.lang[l[d+1:]]; !ok {
+				sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "language_invalid", "{language}", l[d+1:], "{languages}", s.lang.list()))
+				return
+			}
+			v = l[d+1:]
+		}
+		if _, err := s.sql.ExecContext(x, "set_opt_language", m.Chat.ID, v); err != nil {
+			s.logger(x).Error("Received an error when attempting to set the language setting (GID: %d): %s!", m.Chat.ID, err.Error())
+			sendResponse(o, m.Chat.ID, m.MessageID, c.text(s, n, "error_admin"))
+			return
+		}
+		s.invalidate(m.Chat.ID)
+		s.audit(x, m, "swap_language", g.value("swap_language", t), l[d+1:])
+		s.logger(x).Trace(`Admin "%s" set the "swap_language" to "%s" setting for GID %d!`, m.From.String(), l[d+1:], m.Chat.ID)
+		if v != nil {
+			n = l[d+1:]
+		}
+		sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "updated", "{option}", "swap_language", "{value}", l[d+1:]))
+		return
+	case "minimum", "maximum":
+		v, err := strconv.ParseUint(l[d+1:], 10, 8)
+		if err != nil || v < keywordMin || v > keywordMax {
+			sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "usage", "{usage}", "/"+l[:d]+" <keyword length (3 - 16)>"))
+			return
+		}
+		if (l[5:d] == "minimum" && uint8(v) > g.maximum) || (l[5:d] == "maximum" && uint8(v) < g.minimum) {
+			sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "length_invalid", "{minimum}", strconv.Itoa(int(g.minimum)), "{maximum}", strconv.Itoa(int(g.maximum))))
+			return
+		}
+		if _, err = s.sql.ExecContext(x, "set_opt_"+l[5:d], m.Chat.ID, v); err != nil {
+			s.logger(x).Error("Received an error when attempting to set the %s setting (GID: %d): %s!", l[5:d], m.Chat.ID, err.Error())
+			sendResponse(o, m.Chat.ID, m.MessageID, c.text(s, n, "error_admin"))
+			return
+		}
+		s.invalidate(m.Chat.ID)
+		s.audit(x, m, l[:d], g.value(l[:d], t), l[d+1:])
+		s.logger(x).Trace(`Admin "%s" set the "%s" to "%s" setting for GID %d!`, m.From.String(), l[:d], l[d+1:], m.Chat.ID)
+		sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "updated", "{option}", l[:d], "{value}", l[d+1:]))
+		return
+	case "reply":
+		a := -1
+		for i := range replies {
+			if replies[i] == l[d+1:] {
+				a = i
+				break
+			}
+		}
+		if a == -1 {
+			sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "usage", "{usage}", "/swap_reply <parent|original>"))
+			return
+		}
+		if _, err := s.sql.ExecContext(x, "set_opt_reply", m.Chat.ID, a); err != nil {
+			s.logger(x).Error("Received an error when attempting to set the reply setting (GID: %d): %s!", m.Chat.ID, err.Error())
+			sendResponse(o, m.Chat.ID, m.MessageID, c.text(s, n, "error_admin"))
+			return
+		}
+		s.invalidate(m.Chat.ID)
+		s.audit(x, m, "swap_reply", g.value("swap_reply", t), l[d+1:])
+		s.logger(x).Trace(`Admin "%s" set the "swap_reply" to "%s" setting for GID %d!`, m.From.String(), l[d+1:], m.Chat.ID)
+		sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "updated", "{option}", "swap_reply", "{value}", l[d+1:]))
+		return
+	case "attribution":
+		a := -1
+		for i := range attributions {
+			if attributions[i] == l[d+1:] {
+				a = i
+				break
+			}
+		}
+		if a == -1 {
+			sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "usage", "{usage}", "/swap_attribution <none|message|reply>"))
+			return
+		}
+		if _, err := s.sql.ExecContext(x, "set_opt_attribution", m.Chat.ID, a); err != nil {
+			s.logger(x).Error("Received an error when attempting to set the attribution setting (GID: %d): %s!", m.Chat.ID, err.Error())
+			sendResponse(o, m.Chat.ID, m.MessageID, c.text(s, n, "error_admin"))
+			return
+		}
+		s.invalidate(m.Chat.ID)
+		s.audit(x, m, "swap_attribution", g.value("swap_attribution", t), l[d+1:])
+		s.logger(x).Trace(`Admin "%s" set the "swap_attribution" to "%s" setting for GID %d!`, m.From.String(), l[d+1:], m.Chat.ID)
+		sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "updated", "{option}", "swap_attribution", "{value}", l[d+1:]))
+		return
+	case "template":
+		var (
+			r = strings.TrimSpace(m.Text[1:])
+			v any
+		)
+		if r = strings.TrimSpace(r[strings.IndexByte(r, ' ')+1:]); len(r) > 256 {
+			sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "template_length"))
+			return
+		}
+		if l[d+1:] != "reset" {
+			v = r
+		}
+		if _, err := s.sql.ExecContext(x, "set_opt_template", m.Chat.ID, v); err != nil {
+			s.logger(x).Error("Received an error when attempting to set the template setting (GID: %d): %s!", m.Chat.ID, err.Error())
+			sendResponse(o, m.Chat.ID, m.MessageID, c.text(s, n, "error_admin"))
+			return
+		}
+		s.invalidate(m.Chat.ID)
+		s.audit(x, m, "swap_template", g.value("swap_template", t), r)
+		s.logger(x).Trace(`Admin "%s" set the "swap_template" to "%s" setting for GID %d!`, m.From.String(), r, m.Chat.ID)
+		if v == nil {
+			sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "template_reset", "{option}", "swap_template"))
+			return
+		}
+		sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "updated", "{option}", "swap_template", "{value}", r))
+		return
+	case "autodelete":
+		v, err := strconv.ParseUint(l[d+1:], 10, 16)
+		if err != nil {
+			sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "usage", "{usage}", "/swap_autodelete <number of seconds (0 - 65535)>"))
+			return
+		}
+		if _, err := s.sql.ExecContext(x, "set_opt_expire", m.Chat.ID, v); err != nil {
+			s.logger(x).Error("Received an error when attempting to set the autodelete setting (GID: %d): %s!", m.Chat.ID, err.Error())
+			sendResponse(o, m.Chat.ID, m.MessageID, c.text(s, n, "error_admin"))
+			return
+		}
+		s.invalidate(m.Chat.ID)
+		s.audit(x, m, "swap_autodelete", g.value("swap_autodelete", t), l[d+1:])
+		s.logger(x).Trace(`Admin "%s" set the "swap_autodelete" to "%s" setting for GID %d!`, m.From.String(), l[d+1:], m.Chat.ID)
+		sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "updated_seconds", "{option}", "swap_autodelete", "{value}", l[d+1:]))
+		return
+	case "topic_limit", "topic_timeout", "topic_enable":
+		if t == 0 {
+			sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "topic_only"))
+			return
+		}
+		var v any
+		if l[5:d] == "topic_enable" {
+			e, ok := parseBool(l[d+1:])
+			if !ok {
+				sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "usage", "{usage}", "/swap_topic_enable <true|false|1|0|yes|no>"))
+				return
+			}
+			v = e
+		} else {
+			i, err := strconv.ParseUint(l[d+1:], 10, 16)
+			if err != nil {
+				sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "usage", "{usage}", "/"+l[:d]+" <number (0 - 65535)>"))
+				return
+			}
+			v = i
+		}
+		if _, err := s.sql.ExecContext(x, "set_"+l[5:d], m.Chat.ID, t, v); err != nil {
+			s.logger(x).Error("Received an error when attempting to set the %s setting (GID: %d, topic: %d): %s!", l[:d], m.Chat.ID, t, err.Error())
+			sendResponse(o, m.Chat.ID, m.MessageID, c.text(s, n, "error_admin"))
 			return
 		}
-		s.log.Trace(`Admin "%s" set the "swap_delete" to %t setting for GID %d!`, m.From.String(), e, m.Chat.ID)
-		sendResponse(o, m.Chat.ID, m.MessageID, `Sweet! I've updated the "swap_delete" setting to "`+strconv.FormatBool(e)+`"!`)
+		s.invalidate(m.Chat.ID)
+		s.audit(x, m, l[:d]+" #"+strconv.Itoa(t), g.value(l[:d], t), l[d+1:])
+		s.logger(x).Trace(`Admin "%s" set the "%s" to "%s" setting for GID %d topic %d!`, m.From.String(), l[:d], l[d+1:], m.Chat.ID, t)
+		sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "topic_updated", "{option}", l[:d], "{value}", l[d+1:]))
 		return
 	case "timeout":
 		v, err := strconv.ParseUint(l[d+1:], 10, 16)
 		if err != nil || v > 65536 {
-			sendResponse(o, m.Chat.ID, m.MessageID, "Sorry I don't recognize that option value.\n\nThe correct usage should be \"/swap-timeout <number of seconds (0 - 65535)>\"")
+			sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "usage", "{usage}", "/swap_timeout <number of seconds (0 - 65535)>"))
 			return
 		}
 		if _, err := s.sql.ExecContext(x, "set_opt_timeout", m.Chat.ID, v); err != nil {
-			s.log.Error("Received an error when attempting to set timeout setting (GID: %d): %s!", m.Chat.ID, err.Error())
-			sendResponse(o, m.Chat.ID, m.MessageID, errorMessageAdmin)
+			s.logger(x).Error("Received an error when attempting to set timeout setting (GID: %d): %s!", m.Chat.ID, err.Error())
+			sendResponse(o, m.Chat.ID, m.MessageID, c.text(s, n, "error_admin"))
 			return
 		}
-		s.log.Trace(`Admin "%s" set the "swap_timeout" to "%s" setting for GID %d!`, m.From.String(), l[d+1:], m.Chat.ID)
-		delete(s.limits, m.Chat.ID)
-		sendResponse(o, m.Chat.ID, m.MessageID, `Sweet! I've updated the "swap_timeout" setting to `+l[d+1:]+` seconds!`)
+		s.invalidate(m.Chat.ID)
+		s.audit(x, m, "swap_timeout", g.value("swap_timeout", t), l[d+1:])
+		s.logger(x).Trace(`Admin "%s" set the "swap_timeout" to "%s" setting for GID %d!`, m.From.String(), l[d+1:], m.Chat.ID)
+		sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "updated_seconds", "{option}", "swap_timeout", "{value}", l[d+1:]))
 		return
 	default:
 	}
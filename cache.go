@@ -0,0 +1,412 @@
+// Copyright (C) 2021 - 2025 PurpleSec Team
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+
+package swapper
+
+import (
+	"context"
+	"database/sql"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PurpleSec/mapper"
+
+	telegram "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	attributionNone uint8 = iota
+	attributionMessage
+	attributionReply
+)
+
+const (
+	replyParent uint8 = iota
+	replyOriginal
+)
+const (
+	keywordMin = 3
+	keywordMax = 16
+)
+
+var defaults = settings{
+	enabled: true, remove: true, deletable: true, amount: 5, timeout: 5, attribution: attributionMessage,
+	animated: true, video: true, minimum: keywordMin, maximum: keywordMax,
+}
+
+const (
+	stickerStatic uint8 = iota
+	stickerAnimated
+	stickerVideo
+	stickerUnknown uint8 = 0xFF
+)
+
+var (
+	replies      = [...]string{"parent", "original"}
+	attributions = [...]string{"none", "message", "reply"}
+)
+
+type index struct {
+	lock sync.RWMutex
+	m    map[int64]map[string]struct{}
+}
+type banlist struct {
+	lock sync.RWMutex
+	m    map[int64]struct{}
+}
+type block struct {
+	user  int64
+	value string
+}
+type member struct {
+	chat, user int64
+}
+type topic struct {
+	chat   int64
+	thread int
+}
+type override struct {
+	enabled         sql.NullBool
+	amount, timeout sql.NullInt32
+}
+type settings struct {
+	sets            map[string]bool
+	words           map[string]struct{}
+	optout          map[int64]struct{}
+	blocks          map[block]struct{}
+	schedule        []window
+	topics          map[int]override
+	template        string
+	language        string
+	zone            string
+	enabled, remove bool
+	edits, captions bool
+	deletable       bool
+	animated, video bool
+	allowlist       bool
+	silent          bool
+	minimum         uint8
+	maximum         uint8
+	reply           uint8
+	amount, timeout uint16
+	expire          uint16
+	attribution     uint8
+}
+type entry[V any] struct {
+	v V
+	e time.Time
+}
+type expiring[K comparable, V any] struct {
+	lock sync.RWMutex
+	ttl  time.Duration
+	m    map[K]entry[V]
+}
+
+func (g settings) topic(i int64, t int) (bool, uint16, uint16, topic) {
+	v, ok := g.topics[t]
+	if !ok || t == 0 {
+		return g.enabled, g.amount, g.timeout, topic{chat: i}
+	}
+	e, a, d, k := g.enabled, g.amount, g.timeout, topic{chat: i}
+	if v.enabled.Valid {
+		e = v.enabled.Bool
+	}
+	if v.amount.Valid {
+		a, k.thread = uint16(v.amount.Int32), t
+	}
+	if v.timeout.Valid {
+		d, k.thread = uint16(v.timeout.Int32), t
+	}
+	return e, a, d, k
+}
+func (g settings) blocked(u int64, k, i string) bool {
+	if _, ok := g.blocks[block{user: u, value: strings.ToLower(k)}]; ok {
+		return true
+	}
+	_, ok := g.blocks[block{value: i}]
+	return ok && len(i) > 0
+}
+func (g settings) permits(n string, k uint8) bool {
+	// Swaps added before sticker sets were stored are checked again once sent.
+	if k == stickerUnknown {
+		return true
+	}
+	if (k == stickerAnimated && !g.animated) || (k == stickerVideo && !g.video) {
+		return false
+	}
+	if a, ok := g.sets[strings.ToLower(n)]; ok {
+		return a
+	}
+	return !g.allowlist
+}
+func (g settings) value(k string, t int) string {
+	switch k {
+	case "swap_limit":
+		return strconv.Itoa(int(g.amount))
+	case "swap_timeout":
+		return strconv.Itoa(int(g.timeout))
+	case "swap_enable":
+		return strconv.FormatBool(g.enabled)
+	case "swap_delete":
+		return strconv.FormatBool(g.remove)
+	case "swap_edits":
+		return strconv.FormatBool(g.edits)
+	case "swap_captions":
+		return strconv.FormatBool(g.captions)
+	case "swap_animated":
+		return strconv.FormatBool(g.animated)
+	case "swap_video":
+		return strconv.FormatBool(g.video)
+	case "swap_attribution":
+		return attributions[g.attribution]
+	case "swap_template":
+		return g.template
+	case "swap_autodelete":
+		return strconv.Itoa(int(g.expire))
+	case "swap_language":
+		return g.language
+	case "swap_timezone":
+		return g.zone
+	case "swap_minimum":
+		return strconv.Itoa(int(g.minimum))
+	case "swap_maximum":
+		return strconv.Itoa(int(g.maximum))
+	case "swap_silent":
+		return strconv.FormatBool(g.silent)
+	case "swap_reply":
+		return replies[g.reply]
+	case "swap_topic_enable":
+		if v := g.topics[t].enabled; v.Valid {
+			return strconv.FormatBool(v.Bool)
+		}
+	case "swap_topic_limit":
+		if v := g.topics[t].amount; v.Valid {
+			return strconv.Itoa(int(v.Int32))
+		}
+	case "swap_topic_timeout":
+		if v := g.topics[t].timeout; v.Valid {
+			return strconv.Itoa(int(v.Int32))
+		}
+	}
+	return ""
+}
+func (g settings) render(v string, u *telegram.User, k string) string {
+	if len(g.template) > 0 {
+		v = g.template
+	}
+	n := "@" + u.UserName
+	if len(n) <= 1 {
+		n = u.String()
+	}
+	return strings.NewReplacer(
+		"{user}", html.EscapeString(n),
+		"{name}", html.EscapeString(u.String()),
+		"{keyword}", html.EscapeString(k),
+		"{mention}", `<a href="tg://user?id=`+strconv.FormatInt(u.ID, 10)+`">`+html.EscapeString(u.String())+`</a>`,
+	).Replace(html.EscapeString(v))
+}
+func (c *expiring[K, V]) prune() {
+	n := time.Now()
+	c.lock.Lock()
+	for k, v := range c.m {
+		if n.After(v.e) {
+			delete(c.m, k)
+		}
+	}
+	c.lock.Unlock()
+}
+func (c *expiring[K, V]) clear() {
+	c.lock.Lock()
+	for k := range c.m {
+		delete(c.m, k)
+	}
+	c.lock.Unlock()
+}
+func (c *expiring[K, V]) delete(k K) {
+	c.lock.Lock()
+	delete(c.m, k)
+	c.lock.Unlock()
+}
+func (c *expiring[K, V]) take(k K) bool {
+	c.lock.Lock()
+	_, ok := c.m[k]
+	delete(c.m, k)
+	c.lock.Unlock()
+	return ok
+}
+func (c *expiring[K, V]) set(k K, v V) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.lock.Lock()
+	c.m[k] = entry[V]{v: v, e: time.Now().Add(c.ttl)}
+	c.lock.Unlock()
+}
+func (c *expiring[K, V]) get(k K) (V, bool) {
+	c.lock.RLock()
+	v, ok := c.m[k]
+	c.lock.RUnlock()
+	if !ok || time.Now().After(v.e) {
+		var e V
+		return e, false
+	}
+	return v.v, true
+}
+func newExpiring[K comparable, V any](t time.Duration) *expiring[K, V] {
+	return &expiring[K, V]{ttl: t, m: make(map[K]entry[V])}
+}
+func (c *container) admin(x context.Context, s *Swapper, i, u int64) (telegram.ChatMember, error) {
+	// Telegram only sends ChatMember updates to Admin bots, so lookups are not
+	// cached in groups where the bot can't be sure to see demotions.
+	g, err := s.group(x, i)
+	if err != nil {
+		return telegram.ChatMember{}, err
+	}
+	k := member{chat: i, user: u}
+	if v, ok := s.admins.get(k); ok && g.deletable {
+		return v, nil
+	}
+	v, err := c.bot.GetChatMember(telegram.GetChatMemberConfig{
+		ChatConfigWithUser: telegram.ChatConfigWithUser{ChatID: i, UserID: u},
+	})
+	if err != nil {
+		return v, err
+	}
+	if g.deletable {
+		s.admins.set(k, v)
+	}
+	return v, nil
+}
+func (b *banlist) has(i int64) bool {
+	b.lock.RLock()
+	_, ok := b.m[i]
+	b.lock.RUnlock()
+	return ok
+}
+func (b *banlist) add(i int64) {
+	b.lock.Lock()
+	b.m[i] = confirm
+	b.lock.Unlock()
+}
+func (b *banlist) remove(i int64) {
+	b.lock.Lock()
+	delete(b.m, i)
+	b.lock.Unlock()
+}
+func (b *banlist) list() []int64 {
+	b.lock.RLock()
+	v := make([]int64, 0, len(b.m))
+	for i := range b.m {
+		v = append(v, i)
+	}
+	b.lock.RUnlock()
+	sort.Slice(v, func(i, j int) bool { return v[i] < v[j] })
+	return v
+}
+func (b *banlist) load(x context.Context, m *mapper.Map) error {
+	r, err := m.QueryContext(x, "list_bans")
+	if err != nil {
+		return err
+	}
+	var i int64
+	for r.Next() {
+		if err = r.Scan(&i); err != nil {
+			break
+		}
+		b.add(i)
+	}
+	r.Close()
+	return err
+}
+func (i *index) drop(u int64) {
+	i.lock.Lock()
+	delete(i.m, u)
+	i.lock.Unlock()
+}
+func (i *index) add(u int64, k string) {
+	i.lock.Lock()
+	v, ok := i.m[u]
+	if !ok {
+		v = make(map[string]struct{}, 1)
+		i.m[u] = v
+	}
+	v[strings.ToLower(k)] = confirm
+	i.lock.Unlock()
+}
+func (i *index) has(u int64, k string) bool {
+	i.lock.RLock()
+	_, ok := i.m[u][strings.ToLower(k)]
+	i.lock.RUnlock()
+	return ok
+}
+func (i *index) remove(u int64, k string) {
+	i.lock.Lock()
+	if v, ok := i.m[u]; ok {
+		if delete(v, strings.ToLower(k)); len(v) == 0 {
+			delete(i.m, u)
+		}
+	}
+	i.lock.Unlock()
+}
+func (i *index) load(x context.Context, m *mapper.Map) error {
+	r, err := m.QueryContext(x, "list_all")
+	if err != nil {
+		return err
+	}
+	var (
+		u int64
+		k string
+	)
+	for r.Next() {
+		if err = r.Scan(&u, &k); err != nil {
+			break
+		}
+		i.add(u, k)
+	}
+	r.Close()
+	return err
+}
+func (i *index) reload(x context.Context, m *mapper.Map, u int64) error {
+	r, err := m.QueryContext(x, "list", u)
+	if err != nil {
+		return err
+	}
+	var (
+		k string
+		v = make(map[string]struct{})
+	)
+	for r.Next() {
+		if err = r.Scan(&k); err != nil {
+			break
+		}
+		v[strings.ToLower(k)] = confirm
+	}
+	if r.Close(); err != nil {
+		return err
+	}
+	i.lock.Lock()
+	if len(v) == 0 {
+		delete(i.m, u)
+	} else {
+		i.m[u] = v
+	}
+	i.lock.Unlock()
+	return nil
+}
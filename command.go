@@ -32,6 +32,7 @@ You can use the following commands:
 /get <word> - Get the sticker assigned to the word
 /remove [word] - Remove a swapped word
 
+/language [code] - Show or change the language I use
 /list - List all your swapped words
 /clear - Remove all your swapped words
 /help - More information about me!
@@ -41,47 +42,29 @@ If you send me a Sticker, I can tell you what words you have assigned to it!`
 
 Please try again later.`
 	helpMessageExtra = `
-My name is SwapItBot!
+My name is {bot_name}!
 
 My job is to swap out the messages you send with your assigned stickers!
 Use the "/add <word>" to tell me a word and then send a Sticker for me to swap it with.
 If I'm in a group that you're posting in, I will replace any of your set swap words.
 
 I can also be used inline (inside the message box)!
-Try this in any chat (I don't have to be in it) by entering @SwapItBot <word>
+Try this in any chat (I don't have to be in it) by entering {bot} <word>
 
 If you're an Admin of a group and would like to use me, have no fear!
 I have some options that can set (per-group) to set limits on how many swaps I can do.
 (These commands have to be used in the Group that you want to set the options in).
 
-Here's the options that I have for Admins:
+Use the "/swap_help" command in the Group to see the options that I have for Admins.
 (All of these commands require Admin permissions, else I'll ignore them).
 
-/swap_help
- - Show a help message.
+Please message my maintainers ({maintainers}) for more info or questions!
 
-/swap_options
- - Show the settings that you've configured for the Group.
-
-/swap_limit <number of swaps (0 - 65535)>
- - Set a number of times I can perform a swap during the Timeout period. Set to zero to disable.
-
-/swap_timeout <number of seconds (0 - 65535)>
- - Set a time (in seconds) that I can perform swaps that count twords the Limit count. Set to zero to disable.
-
-/swap_delete <true|false|1|0|yes|no>
- - Determines if I will attempt to delete the swapped message (I can only delete if I have the permissions).
-
-/swap_enable <true|false|1|0|yes|no>
- - Master switch to enable or disable swapping messages in this chat.
-
-Please message my maintainers (@secfurry or @iDigitalFlame) for more info or questions!
-
-My source code is located here: https://github.com/PurpleSec/swapper`
-	helpMessageBasic = `Hello there, I'm SwapItBot!
+My source code is located here: {source}`
+	helpMessageBasic = `Hello there, I'm {bot_name}!
 
 I can swap or suggest stickers by a set word or parse!
-You can call me inline  (inside the message box) by entering @SwapItBot <word>
+You can call me inline  (inside the message box) by entering {bot} <word>
 
 If I'm added in a group chat, I can automatically swap out words with stickers!
 
@@ -91,6 +74,7 @@ I have the following commands:
 /get <word> - Get the sticker assigned to the word
 /remove <word> - Remove a swapped word
 
+/language [code] - Show or change the language I use
 /list - List all your swapped words
 /clear - Remove all your swapped words
 /help - More information about me!`
@@ -104,6 +88,17 @@ var builders = sync.Pool{
 
 var confirm struct{}
 
+var commandsUser = [...]string{"add", "get", "remove", "list", "clear", "language", "help"}
+var commandsGroup = [...]string{"swap_optout", "swap_optin"}
+var commandsAdmin = [...]string{
+	"swap_help", "swap_options", "swap_history", "swap_enable", "swap_delete", "swap_limit", "swap_timeout", "swap_edits",
+	"swap_captions", "swap_animated", "swap_video", "swap_allowset", "swap_blockset", "swap_removeset",
+	"swap_block", "swap_unblock", "swap_blockword", "swap_unblockword", "swap_optout", "swap_optin",
+	"swap_language", "swap_attribution", "swap_template", "swap_autodelete", "swap_timezone", "swap_quiet",
+	"swap_minimum", "swap_maximum", "swap_silent", "swap_reply",
+	"swap_topic_enable", "swap_topic_limit", "swap_topic_timeout", "swap_topic_reset",
+}
+
 func (s *Swapper) clearUser(i int64) {
 	s.lock.Lock()
 	delete(s.add, i)
@@ -144,20 +139,20 @@ func (s *Swapper) setUserAdd(i int64, v string) {
 	s.add[i] = v
 	s.lock.Unlock()
 }
-func (s *Swapper) list(x context.Context, i int64) string {
+func (s *Swapper) list(x context.Context, i int64, l string) string {
 	r, err := s.sql.QueryContext(x, "list", i)
 	if err != nil {
-		s.log.Error("Received an error when attempting to list the user swaps (UID: %d): %s!", i, err.Error())
-		return errorMessage
+		s.logger(x).Error("Received an error when attempting to list the user swaps (UID: %d): %s!", i, err.Error())
+		return s.lang.get(l, "error")
 	}
 	var (
 		c int
 		n string
 		b = builders.Get().(*strings.Builder)
 	)
-	for b.WriteString("You are currently swapping the words:\n"); r.Next(); {
+	for b.WriteString(s.lang.get(l, "list_header")); r.Next(); {
 		if err := r.Scan(&n); err != nil {
-			s.log.Error("Received an error when attempting to scan the user swaps (UID: %d): %s!", i, err.Error())
+			s.logger(x).Error("Received an error when attempting to scan the user swaps (UID: %d): %s!", i, err.Error())
 			continue
 		}
 		if len(n) == 0 {
@@ -170,48 +165,60 @@ func (s *Swapper) list(x context.Context, i int64) string {
 	o := b.String()
 	b.Reset()
 	if builders.Put(b); c == 0 {
-		return "You currently have no swapped words set."
+		return s.lang.get(l, "list_empty")
 	}
 	return o
 }
-func (s *Swapper) clear(x context.Context, i int64) string {
+func (s *Swapper) clear(x context.Context, i int64, l string) string {
 	if _, err := s.sql.ExecContext(x, "clear", i); err != nil {
-		s.log.Error("Received an error when attempting to clear the user swaps (UID: %d): %s!", i, err.Error())
-		return errorMessage
+		s.logger(x).Error("Received an error when attempting to clear the user swaps (UID: %d): %s!", i, err.Error())
+		return s.lang.get(l, "error")
 	}
-	return "Sweet! I've cleared your swap list!"
+	s.words.drop(i)
+	return s.lang.get(l, "clear_done")
 }
-func (s *Swapper) sticker(x context.Context, m *telegram.Message) string {
+func (s *Swapper) sticker(x context.Context, m *telegram.Message, z bool, l string) string {
 	if m.Sticker == nil {
-		return "Sorry, but I require a Sticker.\n\nPlease invoke the previous command to try again."
+		return s.lang.get(l, "sticker_required")
 	}
 	if s.getUserDelete(m.From.ID) {
 		if _, err := s.sql.ExecContext(x, "del_swap_sticker", m.From.ID, m.Sticker.FileUniqueID); err != nil {
-			s.log.Error("Received an error when attempting to del the user swap (UID: %d): %s!", m.From.ID, err.Error())
-			return errorMessage
+			s.logger(x).Error("Received an error when attempting to del the user swap (UID: %d): %s!", m.From.ID, err.Error())
+			return s.lang.get(l, "error")
+		}
+		if err := s.words.reload(x, s.sql, m.From.ID); err != nil {
+			s.logger(x).Error("Received an error when attempting to reload the user swaps (UID: %d): %s!", m.From.ID, err.Error())
 		}
-		return "Sweet! I've removed the swap word(s) associated with that sticker!"
+		return s.lang.get(l, "remove_sticker")
 	}
 	if v := s.getUserAdd(m.From.ID); len(v) > 0 {
-		if _, err := s.sql.ExecContext(x, "set_swap", m.From.ID, v, m.Sticker.FileID, m.Sticker.FileUniqueID); err != nil {
-			s.log.Error("Received an error when attempting to add a user swap (UID: %d): %s!", m.From.ID, err.Error())
-			return errorMessage
+		k := stickerStatic
+		switch {
+		case z:
+			k = stickerVideo
+		case m.Sticker.IsAnimated:
+			k = stickerAnimated
 		}
-		return `Sweet! I added the sticker to the swap word "` + v + `"!`
+		if _, err := s.sql.ExecContext(x, "set_swap", m.From.ID, v, m.Sticker.FileID, m.Sticker.FileUniqueID, m.Sticker.SetName, k); err != nil {
+			s.logger(x).Error("Received an error when attempting to add a user swap (UID: %d): %s!", m.From.ID, err.Error())
+			return s.lang.get(l, "error")
+		}
+		s.words.add(m.From.ID, v)
+		return s.lang.get(l, "add_done", "{word}", v)
 	}
 	r, err := s.sql.QueryContext(x, "check_swap", m.From.ID, m.Sticker.FileUniqueID)
 	if err != nil {
-		s.log.Error("Received an error when attempting to check a user swap (UID: %d): %s!", m.From.ID, err.Error())
-		return errorMessage
+		s.logger(x).Error("Received an error when attempting to check a user swap (UID: %d): %s!", m.From.ID, err.Error())
+		return s.lang.get(l, "error")
 	}
 	var (
 		c int
 		n string
 		b = builders.Get().(*strings.Builder)
 	)
-	for b.WriteString("That sticker is tied to the following word(s):\n"); r.Next(); {
+	for b.WriteString(s.lang.get(l, "sticker_header")); r.Next(); {
 		if err := r.Scan(&n); err != nil {
-			s.log.Error("Received an error when attempting to scan the user swaps (UID: %d): %s!", m.From.ID, err.Error())
+			s.logger(x).Error("Received an error when attempting to scan the user swaps (UID: %d): %s!", m.From.ID, err.Error())
 			continue
 		}
 		if len(n) == 0 {
@@ -224,23 +231,27 @@ func (s *Swapper) sticker(x context.Context, m *telegram.Message) string {
 	o := b.String()
 	b.Reset()
 	if builders.Put(b); c == 0 {
-		return "You don't have that Sticker assigned to any swap words.\nUse the \"/add <word>\" command to add it!"
+		return s.lang.get(l, "sticker_empty")
 	}
 	return o
 }
-func (s *Swapper) command(x context.Context, m *telegram.Message, o chan<- telegram.Chattable) {
+func (c *container) command(x context.Context, s *Swapper, m *telegram.Message, z bool, o chan<- telegram.Chattable) {
+	if s.bans.has(m.From.ID) {
+		return
+	}
+	n := s.language(x, m.From)
 	if m.Sticker != nil {
-		o <- telegram.NewMessage(m.Chat.ID, s.sticker(x, m))
+		o <- telegram.NewMessage(m.Chat.ID, s.sticker(x, m, z, n))
 		s.clearUser(m.From.ID)
 		return
 	}
 	ok := s.getUserConfirm(m.From.ID)
 	if s.clearUser(m.From.ID); ok && strings.EqualFold(m.Text, "confirm") {
-		o <- telegram.NewMessage(m.Chat.ID, s.clear(x, m.From.ID))
+		o <- telegram.NewMessage(m.Chat.ID, s.clear(x, m.From.ID, n))
 		return
 	}
 	if len(m.Text) <= 1 {
-		o <- telegram.NewMessage(m.Chat.ID, helpMessage)
+		o <- telegram.NewMessage(m.Chat.ID, c.text(s, n, "help"))
 		return
 	}
 	var (
@@ -250,75 +261,127 @@ func (s *Swapper) command(x context.Context, m *telegram.Message, o chan<- teleg
 	if d == -1 {
 		switch strings.ToLower(l) {
 		case "help":
-			o <- telegram.NewMessage(m.Chat.ID, helpMessageExtra)
+			o <- telegram.NewMessage(m.Chat.ID, c.text(s, n, "help_extra"))
 		case "list":
-			o <- telegram.NewMessage(m.Chat.ID, s.list(x, m.From.ID))
+			o <- telegram.NewMessage(m.Chat.ID, s.list(x, m.From.ID, n))
 		case "clear":
 			s.setUserConfirm(m.From.ID)
-			o <- telegram.NewMessage(m.Chat.ID, `Please reply with "confirm" in order to clear your list.`)
+			o <- telegram.NewMessage(m.Chat.ID, s.lang.get(n, "clear_confirm"))
 		case "start":
-			o <- telegram.NewMessage(m.Chat.ID, helpMessageBasic)
+			s.started(x, m.From.ID)
+			o <- telegram.NewMessage(m.Chat.ID, c.text(s, n, "help_basic"))
 		case "remove":
 			s.setUserDelete(m.From.ID)
-			o <- telegram.NewMessage(m.Chat.ID, "Please reply with the sticker you whish to delete from your swap list.")
+			o <- telegram.NewMessage(m.Chat.ID, s.lang.get(n, "remove_prompt"))
+		case "language":
+			o <- telegram.NewMessage(m.Chat.ID, s.lang.get(n, "language_current", "{language}", n, "{languages}", s.lang.list()))
 		default:
-			o <- telegram.NewMessage(m.Chat.ID, helpMessage)
+			o <- telegram.NewMessage(m.Chat.ID, c.text(s, n, "help"))
 		}
 		return
 	}
 	if d < 3 {
-		o <- telegram.NewMessage(m.Chat.ID, helpMessage)
+		o <- telegram.NewMessage(m.Chat.ID, c.text(s, n, "help"))
 		return
 	}
 	v := strings.TrimSpace(l[d+1:])
 	if len(v) == 0 {
-		o <- telegram.NewMessage(m.Chat.ID, helpMessage)
+		o <- telegram.NewMessage(m.Chat.ID, c.text(s, n, "help"))
+		return
+	}
+	if strings.EqualFold(l[:d], "language") {
+		o <- telegram.NewMessage(m.Chat.ID, s.setLanguage(x, m.From, v, n))
 		return
 	}
-	if len(v) > 16 || len(v) < 3 {
-		o <- telegram.NewMessage(m.Chat.ID, "Sorry, but swapped words must be at least 3 characters and limited to a max of 16 characters!")
+	if len(v) > keywordMax || len(v) < keywordMin {
+		o <- telegram.NewMessage(m.Chat.ID, s.lang.get(n, "word_length"))
 		return
 	}
 	switch strings.ToLower(l[:d]) {
 	case "add":
-		s.add[m.From.ID] = v
-		o <- telegram.NewMessage(m.Chat.ID, `OK! Send me a sticker to swap for "`+v+`"`)
+		s.setUserAdd(m.From.ID, v)
+		o <- telegram.NewMessage(m.Chat.ID, s.lang.get(n, "add_prompt", "{word}", v))
 		return
 	case "get":
 		r, err := s.sql.QueryContext(x, "get_swap", m.From.ID, v)
 		if err != nil {
-			s.log.Error("Received an error when attempting to get a user swap (UID: %d): %s!", m.From.ID, err.Error())
-			o <- telegram.NewMessage(m.Chat.ID, errorMessage)
+			s.logger(x).Error("Received an error when attempting to get a user swap (UID: %d): %s!", m.From.ID, err.Error())
+			o <- telegram.NewMessage(m.Chat.ID, c.text(s, n, "error"))
 			return
 		}
-		var n string
+		var k string
 		for r.Next() {
-			if err = r.Scan(&n); err != nil {
+			if err = r.Scan(&k); err != nil {
 				break
 			}
 		}
 		if r.Close(); err != nil {
-			s.log.Error("Received an error when attempting to scan a user swap (UID: %d): %s!", m.From.ID, err.Error())
-			o <- telegram.NewMessage(m.Chat.ID, errorMessage)
+			s.logger(x).Error("Received an error when attempting to scan a user swap (UID: %d): %s!", m.From.ID, err.Error())
+			o <- telegram.NewMessage(m.Chat.ID, c.text(s, n, "error"))
 			return
 		}
-		if len(n) == 0 {
-			o <- telegram.NewMessage(m.Chat.ID, `You don't have a sticker mapped for "`+v+`"!`)
+		if len(k) == 0 {
+			o <- telegram.NewMessage(m.Chat.ID, s.lang.get(n, "get_empty", "{word}", v))
 			return
 		}
-		o <- telegram.NewSticker(m.Chat.ID, telegram.FileID(n))
+		o <- telegram.NewSticker(m.Chat.ID, telegram.FileID(k))
 		return
 	case "start":
-		o <- telegram.NewMessage(m.Chat.ID, helpMessageBasic)
+		s.started(x, m.From.ID)
+		o <- telegram.NewMessage(m.Chat.ID, c.text(s, n, "help_basic"))
 		return
 	case "remove":
 		if _, err := s.sql.ExecContext(x, "del_swap", m.From.ID, v); err != nil {
-			s.log.Error("Received an error when attempting to del the user swap (UID: %d): %s!", m.From.ID, err.Error())
-			o <- telegram.NewMessage(m.Chat.ID, errorMessage)
+			s.logger(x).Error("Received an error when attempting to del the user swap (UID: %d): %s!", m.From.ID, err.Error())
+			o <- telegram.NewMessage(m.Chat.ID, c.text(s, n, "error"))
 			return
 		}
-		o <- telegram.NewMessage(m.Chat.ID, `Sweet! I've removed the swap word "`+v+`" (if it existed)!`)
+		s.words.remove(m.From.ID, v)
+		o <- telegram.NewMessage(m.Chat.ID, s.lang.get(n, "remove_done", "{word}", v))
 		return
 	}
-	o <- telegram.NewMessage(m.Chat.ID, helpMessage)
+	o <- telegram.NewMessage(m.Chat.ID, c.text(s, n, "help"))
+}
+func (s *Swapper) setLanguage(x context.Context, u *telegram.User, v, l string) string {
+	var k any
+	if v = strings.ToLower(v); v != "reset" {
+		if _, ok := s.lang[v]; !ok {
+			return s.lang.get(l, "language_invalid", "{language}", v, "{languages}", s.lang.list())
+		}
+		k = v
+	}
+	if _, err := s.sql.ExecContext(x, "set_user_lang", u.ID, k); err != nil {
+		s.logger(x).Error("Received an error when attempting to set the user language (UID: %d): %s!", u.ID, err.Error())
+		return s.lang.get(l, "error")
+	}
+	if s.users.delete(u.ID); k == nil {
+		return s.lang.get(s.language(x, u), "language_reset")
+	}
+	return s.lang.get(v, "language_set", "{language}", v)
+}
+func (c *container) register(s *Swapper) {
+	k := [...]struct {
+		n string
+		s telegram.BotCommandScope
+		v []string
+	}{
+		{"user", telegram.NewBotCommandScopeAllPrivateChats(), commandsUser[:]},
+		{"group", telegram.NewBotCommandScopeAllGroupChats(), commandsGroup[:]},
+		{"Admin", telegram.NewBotCommandScopeAllChatAdministrators(), commandsAdmin[:]},
+	}
+	for n := range s.lang {
+		var l string
+		if n != defaultLanguage {
+			l = n
+		}
+		for _, e := range k {
+			v := make([]telegram.BotCommand, len(e.v))
+			for i := range e.v {
+				v[i] = telegram.BotCommand{Command: e.v[i], Description: s.lang.get(n, "command_"+e.v[i])}
+			}
+			if _, err := c.bot.Request(telegram.NewSetMyCommandsWithScopeAndLanguage(e.s, l, v...)); err != nil {
+				s.log.Warning(`Received an error attempting to register the %s commands for "%s" (language: "%s"): %s`, e.n, c.bot.Self.UserName, n, err.Error())
+			}
+		}
+	}
 }
@@ -17,8 +17,16 @@
 package swapper
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	// Import for the Golang MySQL driver
@@ -37,16 +45,55 @@ const Defaults = `{
 	},
 	"log": {
 		"file": "swapper.log",
-		"level": 2
+		"level": 2,
+		"format": "text"
 	},
-	"telegram_key": ""
-	"telegram_key_alt": ""
+	"languages": "",
+	"cache": {
+		"users": 300000000000,
+		"groups": 300000000000,
+		"admins": 300000000000
+	},
+	"telegram": {
+		"url": "https://api.telegram.org/bot%s/%s",
+		"ca": "",
+		"proxy": "",
+		"timeout": 0,
+		"insecure": false,
+		"dial_timeout": 10000000000,
+		"tls_timeout": 10000000000,
+		"idle_timeout": 90000000000
+	},
+	"brand": {
+		"name": "",
+		"source": "https://github.com/PurpleSec/swapper",
+		"maintainers": ["@secfurry", "@iDigitalFlame"]
+	},
+	"telegram_key": "",
+	"owners": [],
+	"ban_leave": true,
+	"admin_rights": {},
+	"workers": 4,
+	"update_timeout": 30000000000
 }
 `
 
+const (
+	defaultSource   = "https://github.com/PurpleSec/swapper"
+	defaultEndpoint = "https://api.telegram.org/bot%s/%s"
+)
+
+const pollTimeout = time.Second * 5
+
+type cache struct {
+	Users  time.Duration `json:"users"`
+	Groups time.Duration `json:"groups"`
+	Admins time.Duration `json:"admins"`
+}
 type log struct {
-	File  string `json:"file"`
-	Level int    `json:"level"`
+	File   string `json:"file"`
+	Format string `json:"format"`
+	Level  int    `json:"level"`
 }
 type limit struct {
 	free  time.Time
@@ -54,10 +101,41 @@ type limit struct {
 	max   uint16
 	count uint16
 }
+type brand struct {
+	Name        string   `json:"name"`
+	Source      string   `json:"source"`
+	Maintainers []string `json:"maintainers"`
+}
+type rights map[string][]string
+type token struct {
+	API   *api   `json:"api"`
+	Brand *brand `json:"brand"`
+	Key   string `json:"key"`
+}
+type tokens []token
 type config struct {
-	Database database     `json:"db"`
-	Telegram stringOrList `json:"telegram_key"`
-	Log      log          `json:"log"`
+	API      api           `json:"telegram"`
+	Database database      `json:"db"`
+	Telegram tokens        `json:"telegram_key"`
+	Brand    brand         `json:"brand"`
+	Owners   []int64       `json:"owners"`
+	BanLeave bool          `json:"ban_leave"`
+	Rights   rights        `json:"admin_rights"`
+	Log      log           `json:"log"`
+	Cache    cache         `json:"cache"`
+	Language string        `json:"languages"`
+	Workers  int           `json:"workers"`
+	Timeout  time.Duration `json:"update_timeout"`
+}
+type api struct {
+	URL         string        `json:"url"`
+	CA          string        `json:"ca"`
+	Proxy       string        `json:"proxy"`
+	Timeout     time.Duration `json:"timeout"`
+	Insecure    *bool         `json:"insecure"`
+	DialTimeout time.Duration `json:"dial_timeout"`
+	TLSTimeout  time.Duration `json:"tls_timeout"`
+	IdleTimeout time.Duration `json:"idle_timeout"`
 }
 type database struct {
 	Name     string        `json:"database"`
@@ -66,10 +144,6 @@ type database struct {
 	Password string        `json:"password"`
 	Timeout  time.Duration `json:"timeout"`
 }
-type stringOrList struct {
-	s string
-	e []string
-}
 
 func (c *config) check() error {
 	if len(c.Database.Name) == 0 {
@@ -84,11 +158,168 @@ func (c *config) check() error {
 	if c.Database.Timeout == 0 {
 		c.Database.Timeout = time.Minute * 3
 	}
+	if len(c.Telegram) == 0 {
+		return errors.New("no telegram accounts")
+	}
+	if c.API.Timeout > 0 && c.API.Timeout <= pollTimeout {
+		return errors.New("telegram timeout must be zero or longer than " + pollTimeout.String())
+	}
+	for i := range c.Telegram {
+		if len(c.Telegram[i].Key) == 0 {
+			return errors.New("empty telegram key")
+		}
+		if a := c.Telegram[i].API; a != nil && a.Timeout > 0 && a.Timeout <= pollTimeout {
+			return errors.New("telegram key (" + strconv.Itoa(i) + ") timeout must be zero or longer than " + pollTimeout.String())
+		}
+	}
+	switch c.Log.Format = strings.ToLower(c.Log.Format); c.Log.Format {
+	case "":
+		c.Log.Format = "text"
+	case "text", "json":
+	default:
+		return errors.New(`invalid log format "` + c.Log.Format + `"`)
+	}
+	if c.Cache.Users == 0 {
+		c.Cache.Users = time.Minute * 5
+	}
+	if c.Cache.Groups == 0 {
+		c.Cache.Groups = time.Minute * 5
+	}
+	if c.Cache.Admins == 0 {
+		c.Cache.Admins = time.Minute * 5
+	}
+	if c.Workers <= 0 {
+		c.Workers = 4
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = time.Second * 30
+	}
+	if len(c.API.URL) == 0 {
+		c.API.URL = defaultEndpoint
+	}
+	for k, v := range c.Rights {
+		for i := range v {
+			if _, ok := permissions[v[i]]; !ok {
+				return errors.New(`admin right "` + v[i] + `" for "` + k + `" is not valid`)
+			}
+		}
+	}
+	if len(c.Brand.Source) == 0 {
+		c.Brand.Source = defaultSource
+	}
+	if len(c.Brand.Maintainers) == 0 {
+		c.Brand.Maintainers = []string{"@secfurry", "@iDigitalFlame"}
+	}
+	return nil
+}
+func (b brand) merge(o *brand) brand {
+	if o == nil {
+		return b
+	}
+	if len(o.Name) > 0 {
+		b.Name = o.Name
+	}
+	if len(o.Source) > 0 {
+		b.Source = o.Source
+	}
+	if len(o.Maintainers) > 0 {
+		b.Maintainers = o.Maintainers
+	}
+	return b
+}
+func (b brand) replacer(u, n string) []string {
+	if len(b.Name) > 0 {
+		n = b.Name
+	}
+	return []string{"{bot}", "@" + u, "{bot_name}", n, "{maintainers}", strings.Join(b.Maintainers, " or "), "{source}", b.Source}
+}
+func (a api) merge(o *api) api {
+	if o == nil {
+		return a
+	}
+	if len(o.URL) > 0 {
+		a.URL = o.URL
+	}
+	if len(o.CA) > 0 {
+		a.CA = o.CA
+	}
+	if len(o.Proxy) > 0 {
+		a.Proxy = o.Proxy
+	}
+	if o.Timeout > 0 {
+		a.Timeout = o.Timeout
+	}
+	if o.DialTimeout > 0 {
+		a.DialTimeout = o.DialTimeout
+	}
+	if o.TLSTimeout > 0 {
+		a.TLSTimeout = o.TLSTimeout
+	}
+	if o.IdleTimeout > 0 {
+		a.IdleTimeout = o.IdleTimeout
+	}
+	if o.Insecure != nil {
+		a.Insecure = o.Insecure
+	}
+	return a
+}
+func (a api) client() (*http.Client, error) {
+	t := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		DialContext:         (&net.Dialer{Timeout: a.DialTimeout, KeepAlive: time.Second * 30}).DialContext,
+		MaxIdleConns:        256,
+		IdleConnTimeout:     a.IdleTimeout,
+		ForceAttemptHTTP2:   false,
+		TLSHandshakeTimeout: a.TLSTimeout,
+	}
+	if len(a.Proxy) > 0 {
+		u, err := url.Parse(a.Proxy)
+		if err != nil {
+			return nil, errors.New(`proxy "` + a.Proxy + `": ` + err.Error())
+		}
+		t.Proxy = http.ProxyURL(u)
+	}
+	if k := a.Insecure != nil && *a.Insecure; k || len(a.CA) > 0 {
+		t.TLSClientConfig = &tls.Config{InsecureSkipVerify: k}
+	}
+	if len(a.CA) > 0 {
+		b, err := os.ReadFile(a.CA)
+		if err != nil {
+			return nil, errors.New(`reading CA "` + a.CA + `": ` + err.Error())
+		}
+		p, err := x509.SystemCertPool()
+		if err != nil || p == nil {
+			p = x509.NewCertPool()
+		}
+		if !p.AppendCertsFromPEM(b) {
+			return nil, errors.New(`reading CA "` + a.CA + `": no valid certificates`)
+		}
+		t.TLSClientConfig.RootCAs = p
+	}
+	return &http.Client{Timeout: a.Timeout, Transport: t}, nil
+}
+func (t *token) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &t.Key); err == nil {
+		return nil
+	}
+	var v struct {
+		API   *api   `json:"api"`
+		Brand *brand `json:"brand"`
+		Key   string `json:"key"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	t.API, t.Brand, t.Key = v.API, v.Brand, v.Key
 	return nil
 }
-func (s *stringOrList) UnmarshalJSON(b []byte) error {
-	if err := json.Unmarshal(b, &s.e); err == nil {
+func (t *tokens) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		if *t = nil; len(s) > 0 {
+			*t = tokens{{Key: s}}
+		}
 		return nil
 	}
-	return json.Unmarshal(b, &s.s)
+	return json.Unmarshal(b, (*[]token)(t))
 }
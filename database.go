@@ -19,11 +19,25 @@ package swapper
 var cleanStatements = []string{
 	`DROP TABLES IF EXISTS Settings`,
 	`DROP TABLES IF EXISTS Mappings`,
+	`DROP TABLES IF EXISTS Groups`,
+	`DROP TABLES IF EXISTS Topics`,
+	`DROP TABLES IF EXISTS Users`,
+	`DROP TABLES IF EXISTS Bans`,
+	`DROP TABLES IF EXISTS StickerSets`,
+	`DROP TABLES IF EXISTS Keywords`,
+	`DROP TABLES IF EXISTS OptOuts`,
+	`DROP TABLES IF EXISTS Blocks`,
+	`DROP TABLES IF EXISTS Swaps`,
+	`DROP TABLES IF EXISTS Audit`,
+	`DROP TABLES IF EXISTS Schedules`,
+	`DROP PROCEDURE IF EXISTS RemoveGroup`,
 	`DROP PROCEDURE IF EXISTS GetSticker`,
+	`DROP PROCEDURE IF EXISTS MoveGroup`,
 	`DROP PROCEDURE IF EXISTS SetSticker`,
 	`DROP PROCEDURE IF EXISTS SetSettingLimit`,
 	`DROP PROCEDURE IF EXISTS SetSettingTimeout`,
 	`DROP PROCEDURE IF EXISTS SetSettingEnabled`,
+	`DROP PROCEDURE IF EXISTS RemoveUser`,
 }
 
 var setupStatements = []string{
@@ -41,11 +55,98 @@ var setupStatements = []string{
 		Remove BOOLEAN NOT NULL DEFAULT TRUE,
 		Enabled BOOLEAN NOT NULL DEFAULT TRUE
 	)`,
+	`CREATE TABLE IF NOT EXISTS Groups(
+		GroupID BIGINT(64) NOT NULL PRIMARY KEY,
+		CanDelete BOOLEAN NOT NULL DEFAULT FALSE,
+		Added TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`,
+	`CREATE TABLE IF NOT EXISTS Topics(
+		GroupID BIGINT(64) NOT NULL,
+		TopicID INT(32) NOT NULL,
+		Enabled BOOLEAN NULL,
+		Amount INT(16) UNSIGNED NULL,
+		Timeout INT(16) UNSIGNED NULL,
+		PRIMARY KEY(GroupID, TopicID)
+	)`,
+	`CREATE TABLE IF NOT EXISTS Users(
+		UserID BIGINT(64) UNSIGNED NOT NULL PRIMARY KEY,
+		Language VARCHAR(8) NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS StickerSets(
+		GroupID BIGINT(64) NOT NULL,
+		SetName VARCHAR(64) NOT NULL,
+		Allow BOOLEAN NOT NULL,
+		PRIMARY KEY(GroupID, SetName)
+	)`,
+	`CREATE TABLE IF NOT EXISTS Keywords(
+		GroupID BIGINT(64) NOT NULL,
+		Keyword VARCHAR(16) NOT NULL,
+		PRIMARY KEY(GroupID, Keyword)
+	)`,
+	`CREATE TABLE IF NOT EXISTS OptOuts(
+		GroupID BIGINT(64) NOT NULL,
+		UserID BIGINT(64) UNSIGNED NOT NULL,
+		PRIMARY KEY(GroupID, UserID)
+	)`,
+	`CREATE TABLE IF NOT EXISTS Blocks(
+		GroupID BIGINT(64) NOT NULL,
+		UserID BIGINT(64) UNSIGNED NOT NULL DEFAULT 0,
+		Value VARCHAR(128) NOT NULL,
+		PRIMARY KEY(GroupID, UserID, Value)
+	)`,
+	`CREATE TABLE IF NOT EXISTS Swaps(
+		GroupID BIGINT(64) NOT NULL,
+		MessageID INT(32) NOT NULL,
+		UserID BIGINT(64) UNSIGNED NOT NULL,
+		Keyword VARCHAR(16) NOT NULL,
+		StickerUID VARCHAR(128) NOT NULL,
+		Added TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY(GroupID, MessageID)
+	)`,
+	`CREATE TABLE IF NOT EXISTS Audit(
+		AuditID BIGINT(64) UNSIGNED NOT NULL PRIMARY KEY AUTO_INCREMENT,
+		GroupID BIGINT(64) NOT NULL,
+		UserID BIGINT(64) UNSIGNED NOT NULL,
+		Name VARCHAR(128) NOT NULL,
+		Setting VARCHAR(64) NOT NULL,
+		Old VARCHAR(256) NULL,
+		New VARCHAR(256) NULL,
+		Changed TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		INDEX(GroupID)
+	)`,
+	`CREATE TABLE IF NOT EXISTS Schedules(
+		GroupID BIGINT(64) NOT NULL,
+		Start INT(16) UNSIGNED NOT NULL,
+		End INT(16) UNSIGNED NOT NULL,
+		Amount INT(16) UNSIGNED NULL,
+		PRIMARY KEY(GroupID, Start, End)
+	)`,
+	`CREATE TABLE IF NOT EXISTS Bans(
+		ID BIGINT(64) NOT NULL PRIMARY KEY,
+		Added TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`,
 	`ALTER TABLE Mappings ADD COLUMN IF NOT EXISTS (StickerUID VARCHAR(128) NULL)`,
 	`ALTER TABLE Mappings MODIFY SwapID BIGINT(64) UNSIGNED NOT NULL AUTO_INCREMENT`,
 	`ALTER TABLE Mappings MODIFY UserID BIGINT(64) UNSIGNED NOT NULL`,
 	`ALTER TABLE Settings MODIFY Amount INT(16) UNSIGNED NOT NULL DEFAULT 5`,
 	`ALTER TABLE Settings MODIFY Timeout INT(16) UNSIGNED NOT NULL DEFAULT 5`,
+	`ALTER TABLE Settings ADD COLUMN IF NOT EXISTS (Attribution TINYINT(8) UNSIGNED NOT NULL DEFAULT 1)`,
+	`ALTER TABLE Settings ADD COLUMN IF NOT EXISTS (Template VARCHAR(256) NULL)`,
+	`ALTER TABLE Settings ADD COLUMN IF NOT EXISTS (Expire INT(16) UNSIGNED NOT NULL DEFAULT 0)`,
+	`ALTER TABLE Settings ADD COLUMN IF NOT EXISTS (Edits BOOLEAN NOT NULL DEFAULT FALSE)`,
+	`ALTER TABLE Settings ADD COLUMN IF NOT EXISTS (Captions BOOLEAN NOT NULL DEFAULT FALSE)`,
+	`ALTER TABLE Settings ADD COLUMN IF NOT EXISTS (Language VARCHAR(8) NULL)`,
+	`ALTER TABLE Users ADD COLUMN IF NOT EXISTS (Started BOOLEAN NOT NULL DEFAULT FALSE)`,
+	`INSERT IGNORE INTO Users(UserID, Started) SELECT DISTINCT UserID, TRUE FROM Mappings`,
+	`ALTER TABLE Mappings ADD COLUMN IF NOT EXISTS (StickerSet VARCHAR(64) NULL)`,
+	`ALTER TABLE Mappings ADD COLUMN IF NOT EXISTS (StickerType TINYINT(8) UNSIGNED NOT NULL DEFAULT 0)`,
+	`ALTER TABLE Settings ADD COLUMN IF NOT EXISTS (Animated BOOLEAN NOT NULL DEFAULT TRUE)`,
+	`ALTER TABLE Settings ADD COLUMN IF NOT EXISTS (Video BOOLEAN NOT NULL DEFAULT TRUE)`,
+	`ALTER TABLE Settings ADD COLUMN IF NOT EXISTS (Timezone VARCHAR(64) NULL)`,
+	`ALTER TABLE Settings ADD COLUMN IF NOT EXISTS (MinLength TINYINT(8) UNSIGNED NOT NULL DEFAULT 3)`,
+	`ALTER TABLE Settings ADD COLUMN IF NOT EXISTS (MaxLength TINYINT(8) UNSIGNED NOT NULL DEFAULT 16)`,
+	`ALTER TABLE Settings ADD COLUMN IF NOT EXISTS (Silent BOOLEAN NOT NULL DEFAULT FALSE)`,
+	`ALTER TABLE Settings ADD COLUMN IF NOT EXISTS (ReplyMode TINYINT(8) UNSIGNED NOT NULL DEFAULT 0)`,
 	`CREATE PROCEDURE IF NOT EXISTS SetSettingDelete(GID BIGINT(64), Remove BOOLEAN)
 	BEGIN
 		SET @gid = COALESCE((SELECT GroupID FROM Settings WHERE GroupID = GID LIMIT 1), 0);
@@ -82,40 +183,176 @@ var setupStatements = []string{
 			UPDATE Settings SET Timeout = Timeout WHERE GroupID = GID;
 		END IF;
 	END;`,
-	`CREATE PROCEDURE IF NOT EXISTS GetSticker(User BIGINT(64) UNSIGNED, GID BIGINT(64), Word VARCHAR(16))
+	`DROP PROCEDURE IF EXISTS GetSticker`,
+	`DROP PROCEDURE IF EXISTS RemoveGroup`,
+	`CREATE PROCEDURE RemoveGroup(GID BIGINT(64))
 	BEGIN
-		SET @gid = COALESCE((SELECT GroupID FROM Settings WHERE GroupID = GID LIMIT 1), 0);
-		IF @gid = 0 THEN
-			INSERT INTO Settings(GroupID) VALUES(GID);
+		DECLARE EXIT HANDLER FOR SQLEXCEPTION
+		BEGIN
+			ROLLBACK;
+			RESIGNAL;
+		END;
+		START TRANSACTION;
+		DELETE FROM Groups WHERE GroupID = GID;
+		DELETE FROM Topics WHERE GroupID = GID;
+		DELETE FROM Settings WHERE GroupID = GID;
+		DELETE FROM StickerSets WHERE GroupID = GID;
+		DELETE FROM Keywords WHERE GroupID = GID;
+		DELETE FROM OptOuts WHERE GroupID = GID;
+		DELETE FROM Blocks WHERE GroupID = GID;
+		DELETE FROM Swaps WHERE GroupID = GID;
+		DELETE FROM Audit WHERE GroupID = GID;
+		DELETE FROM Schedules WHERE GroupID = GID;
+		COMMIT;
+	END;`,
+	`DROP PROCEDURE IF EXISTS MoveGroup`,
+	`CREATE PROCEDURE MoveGroup(Old BIGINT(64), New BIGINT(64))
+	BEGIN
+		DECLARE EXIT HANDLER FOR SQLEXCEPTION
+		BEGIN
+			ROLLBACK;
+			RESIGNAL;
+		END;
+		START TRANSACTION;
+		IF EXISTS(SELECT GroupID FROM Settings WHERE GroupID = Old) THEN
+			DELETE FROM Settings WHERE GroupID = New;
+			UPDATE Settings SET GroupID = New WHERE GroupID = Old;
+		END IF;
+		IF EXISTS(SELECT GroupID FROM Groups WHERE GroupID = Old) THEN
+			DELETE FROM Groups WHERE GroupID = New;
+			UPDATE Groups SET GroupID = New WHERE GroupID = Old;
+		END IF;
+		IF EXISTS(SELECT GroupID FROM Topics WHERE GroupID = Old) THEN
+			DELETE FROM Topics WHERE GroupID = New;
+			UPDATE Topics SET GroupID = New WHERE GroupID = Old;
 		END IF;
-		SELECT Enabled, Amount, Timeout, Remove, COALESCE((SELECT StickerID FROM Mappings WHERE UserID = User AND Keyword = Word LIMIT 1), "") As StickerID
-			FROM Settings WHERE GroupID = GID;
+		IF EXISTS(SELECT GroupID FROM StickerSets WHERE GroupID = Old) THEN
+			DELETE FROM StickerSets WHERE GroupID = New;
+			UPDATE StickerSets SET GroupID = New WHERE GroupID = Old;
+		END IF;
+		IF EXISTS(SELECT GroupID FROM Keywords WHERE GroupID = Old) THEN
+			DELETE FROM Keywords WHERE GroupID = New;
+			UPDATE Keywords SET GroupID = New WHERE GroupID = Old;
+		END IF;
+		IF EXISTS(SELECT GroupID FROM OptOuts WHERE GroupID = Old) THEN
+			DELETE FROM OptOuts WHERE GroupID = New;
+			UPDATE OptOuts SET GroupID = New WHERE GroupID = Old;
+		END IF;
+		IF EXISTS(SELECT GroupID FROM Blocks WHERE GroupID = Old) THEN
+			DELETE FROM Blocks WHERE GroupID = New;
+			UPDATE Blocks SET GroupID = New WHERE GroupID = Old;
+		END IF;
+		IF EXISTS(SELECT GroupID FROM Schedules WHERE GroupID = Old) THEN
+			DELETE FROM Schedules WHERE GroupID = New;
+			UPDATE Schedules SET GroupID = New WHERE GroupID = Old;
+		END IF;
+		UPDATE Audit SET GroupID = New WHERE GroupID = Old;
+		COMMIT;
+	END;`,
+	`DROP PROCEDURE IF EXISTS RemoveUser`,
+	`CREATE PROCEDURE RemoveUser(UID BIGINT(64) UNSIGNED)
+	BEGIN
+		DECLARE EXIT HANDLER FOR SQLEXCEPTION
+		BEGIN
+			ROLLBACK;
+			RESIGNAL;
+		END;
+		START TRANSACTION;
+		DELETE FROM Mappings WHERE UserID = UID;
+		DELETE FROM Users WHERE UserID = UID;
+		DELETE FROM OptOuts WHERE UserID = UID;
+		DELETE FROM Blocks WHERE UserID = UID;
+		DELETE FROM Swaps WHERE UserID = UID;
+		DELETE FROM Audit WHERE UserID = UID;
+		COMMIT;
 	END;`,
-	`CREATE PROCEDURE IF NOT EXISTS SetSticker(User BIGINT(64) UNSIGNED, Word VARCHAR(16), Sticker VARCHAR(128), SID VARCHAR(128))
+	`DROP PROCEDURE IF EXISTS SetSticker`,
+	`CREATE PROCEDURE SetSticker(User BIGINT(64) UNSIGNED, Word VARCHAR(16), Sticker VARCHAR(128), SID VARCHAR(128), Pack VARCHAR(64), Kind TINYINT(8) UNSIGNED)
 	BEGIN
 		SET @sid = COALESCE((SELECT SwapID FROM Mappings WHERE UserID = User AND Keyword = Word LIMIT 1), 0);
 		IF @sid > 0 THEN
-			UPDATE Mappings SET StickerID = Sticker, StickerUID = SID WHERE SwapID = @sid;
+			UPDATE Mappings SET StickerID = Sticker, StickerUID = SID, StickerSet = Pack, StickerType = Kind WHERE SwapID = @sid;
 		ELSE
-			INSERT INTO Mappings(UserID, StickerID, StickerUID, Keyword) VALUES(User, Sticker, SID, Word);
+			INSERT INTO Mappings(UserID, StickerID, StickerUID, StickerSet, StickerType, Keyword) VALUES(User, Sticker, SID, Pack, Kind, Word);
 		END IF;
 	END;`,
 }
 
 var queryStatements = map[string]string{
-	"swap":             `CALL GetSticker(?, ?, ?)`,
-	"list":             `SELECT Keyword FROM Mappings where UserID = ?`,
-	"clear":            `DELETE FROM Mappings where UserID = ?`,
-	"inline":           `SELECT StickerID FROM Mappings WHERE UserID = ? AND Keyword LIKE ?`,
-	"get_swap":         `SELECT StickerID FROM Mappings WHERE UserID = ? AND Keyword = ?`,
-	"set_swap":         `CALL SetSticker(?, ?, ?, ?)`,
-	"del_swap":         `DELETE FROM Mappings WHERE UserID = ? AND Keyword = ?`,
-	"list_opt":         `SELECT Enabled, Amount, Timeout, Remove FROM Settings WHERE GroupID = ?`,
-	"inline_all":       `SELECT StickerID FROM Mappings WHERE UserID = ?`,
-	"check_swap":       `SELECT Keyword FROM Mappings WHERE UserID = ? AND StickerUID = ?`,
-	"set_opt_limit":    `CALL SetSettingLimit(?, ?)`,
-	"set_opt_delete":   `CALL SetSettingDelete(?, ?)`,
-	"set_opt_enable":   `CALL SetSettingEnabled(?, ?)`,
-	"set_opt_timeout":  `CALL SetSettingTimeout(?, ?)`,
-	"del_swap_sticker": `DELETE FROM Mappings WHERE UserID = ? AND StickerUID = ?`,
+	"list":                `SELECT Keyword FROM Mappings where UserID = ?`,
+	"list_all":            `SELECT UserID, Keyword FROM Mappings`,
+	"clear":               `DELETE FROM Mappings where UserID = ?`,
+	"inline":              `SELECT StickerID FROM Mappings WHERE UserID = ? AND Keyword LIKE ?`,
+	"get_swap":            `SELECT StickerID FROM Mappings WHERE UserID = ? AND Keyword = ?`,
+	"set_swap":            `CALL SetSticker(?, ?, ?, ?, ?, ?)`,
+	"del_swap":            `DELETE FROM Mappings WHERE UserID = ? AND Keyword = ?`,
+	"add_group":           `INSERT INTO Groups(GroupID, CanDelete) VALUES(?, ?) ON DUPLICATE KEY UPDATE CanDelete = VALUES(CanDelete)`,
+	"get_group":           `SELECT CanDelete FROM Groups WHERE GroupID = ?`,
+	"del_group":           `CALL RemoveGroup(?)`,
+	"move_group":          `CALL MoveGroup(?, ?)`,
+	"del_topic":           `DELETE FROM Topics WHERE GroupID = ? AND TopicID = ?`,
+	"list_topic":          `SELECT TopicID, Enabled, Amount, Timeout FROM Topics WHERE GroupID = ?`,
+	"list_opt":            `SELECT Enabled, Amount, Timeout, Remove, Attribution, COALESCE(Template, ""), Expire, Edits, Captions, COALESCE(Language, ""), Animated, Video, COALESCE(Timezone, ""), MinLength, MaxLength, Silent, ReplyMode FROM Settings WHERE GroupID = ?`,
+	"clean_opt":           `DELETE FROM Settings WHERE Enabled = TRUE AND Remove = TRUE AND Amount = 5 AND Timeout = 5 AND Attribution = 1 AND Template IS NULL AND Expire = 0 AND Edits = FALSE AND Captions = FALSE AND Language IS NULL AND Animated = TRUE AND Video = TRUE AND Timezone IS NULL AND MinLength = 3 AND MaxLength = 16 AND Silent = FALSE AND ReplyMode = 0`,
+	"inline_all":          `SELECT StickerID FROM Mappings WHERE UserID = ?`,
+	"check_swap":          `SELECT Keyword FROM Mappings WHERE UserID = ? AND StickerUID = ?`,
+	"set_opt_limit":       `CALL SetSettingLimit(?, ?)`,
+	"set_opt_delete":      `CALL SetSettingDelete(?, ?)`,
+	"set_opt_enable":      `CALL SetSettingEnabled(?, ?)`,
+	"set_opt_timeout":     `CALL SetSettingTimeout(?, ?)`,
+	"set_topic_limit":     `INSERT INTO Topics(GroupID, TopicID, Amount) VALUES(?, ?, ?) ON DUPLICATE KEY UPDATE Amount = VALUES(Amount)`,
+	"set_topic_enable":    `INSERT INTO Topics(GroupID, TopicID, Enabled) VALUES(?, ?, ?) ON DUPLICATE KEY UPDATE Enabled = VALUES(Enabled)`,
+	"set_topic_timeout":   `INSERT INTO Topics(GroupID, TopicID, Timeout) VALUES(?, ?, ?) ON DUPLICATE KEY UPDATE Timeout = VALUES(Timeout)`,
+	"set_opt_expire":      `INSERT INTO Settings(GroupID, Expire) VALUES(?, ?) ON DUPLICATE KEY UPDATE Expire = VALUES(Expire)`,
+	"set_opt_template":    `INSERT INTO Settings(GroupID, Template) VALUES(?, ?) ON DUPLICATE KEY UPDATE Template = VALUES(Template)`,
+	"set_opt_attribution": `INSERT INTO Settings(GroupID, Attribution) VALUES(?, ?) ON DUPLICATE KEY UPDATE Attribution = VALUES(Attribution)`,
+	"set_opt_edits":       `INSERT INTO Settings(GroupID, Edits) VALUES(?, ?) ON DUPLICATE KEY UPDATE Edits = VALUES(Edits)`,
+	"set_opt_captions":    `INSERT INTO Settings(GroupID, Captions) VALUES(?, ?) ON DUPLICATE KEY UPDATE Captions = VALUES(Captions)`,
+	"get_user_lang":       `SELECT COALESCE(Language, "") FROM Users WHERE UserID = ?`,
+	"set_user_lang":       `INSERT INTO Users(UserID, Language) VALUES(?, ?) ON DUPLICATE KEY UPDATE Language = VALUES(Language)`,
+	"set_opt_language":    `INSERT INTO Settings(GroupID, Language) VALUES(?, ?) ON DUPLICATE KEY UPDATE Language = VALUES(Language)`,
+	"del_swap_sticker":    `DELETE FROM Mappings WHERE UserID = ? AND StickerUID = ?`,
+	"add_user":            `INSERT INTO Users(UserID, Started) VALUES(?, TRUE) ON DUPLICATE KEY UPDATE Started = TRUE`,
+	"get_user":            `SELECT (SELECT COUNT(*) FROM Mappings WHERE UserID = ?), COALESCE((SELECT Language FROM Users WHERE UserID = ?), ""), COALESCE((SELECT Started FROM Users WHERE UserID = ?), FALSE)`,
+	"del_user":            `CALL RemoveUser(?)`,
+	"stop_user":           `UPDATE Users SET Started = FALSE WHERE UserID = ?`,
+	"list_users":          `SELECT UserID FROM Users WHERE Started = TRUE`,
+	"list_groups":         `SELECT Groups.GroupID, Groups.CanDelete, COALESCE(Settings.Enabled, TRUE) FROM Groups LEFT JOIN Settings ON Settings.GroupID = Groups.GroupID ORDER BY Groups.Added DESC LIMIT 100`,
+	"add_set":             `INSERT INTO StickerSets(GroupID, SetName, Allow) VALUES(?, ?, ?) ON DUPLICATE KEY UPDATE Allow = VALUES(Allow)`,
+	"del_set":             `DELETE FROM StickerSets WHERE GroupID = ? AND SetName = ?`,
+	"list_sets":           `SELECT SetName, Allow FROM StickerSets WHERE GroupID = ?`,
+	"clear_sets":          `DELETE FROM StickerSets WHERE GroupID = ? AND Allow = ?`,
+	"get_sticker":         `SELECT StickerID, COALESCE(StickerUID, ""), COALESCE(StickerSet, ""), IF(StickerSet IS NULL, 255, StickerType) FROM Mappings WHERE UserID = ? AND Keyword = ?`,
+	"fill_sticker":        `UPDATE Mappings SET StickerSet = ?, StickerType = ? WHERE UserID = ? AND Keyword = ? AND StickerSet IS NULL`,
+	"set_opt_video":       `INSERT INTO Settings(GroupID, Video) VALUES(?, ?) ON DUPLICATE KEY UPDATE Video = VALUES(Video)`,
+	"set_opt_animated":    `INSERT INTO Settings(GroupID, Animated) VALUES(?, ?) ON DUPLICATE KEY UPDATE Animated = VALUES(Animated)`,
+	"add_word":            `INSERT IGNORE INTO Keywords(GroupID, Keyword) VALUES(?, ?)`,
+	"del_word":            `DELETE FROM Keywords WHERE GroupID = ? AND Keyword = ?`,
+	"list_words":          `SELECT Keyword FROM Keywords WHERE GroupID = ?`,
+	"add_optout":          `INSERT IGNORE INTO OptOuts(GroupID, UserID) VALUES(?, ?)`,
+	"del_optout":          `DELETE FROM OptOuts WHERE GroupID = ? AND UserID = ?`,
+	"list_optout":         `SELECT UserID FROM OptOuts WHERE GroupID = ?`,
+	"add_block":           `INSERT IGNORE INTO Blocks(GroupID, UserID, Value) VALUES(?, ?, ?)`,
+	"del_block":           `DELETE FROM Blocks WHERE GroupID = ? AND UserID = ? AND Value = ?`,
+	"list_blocks":         `SELECT UserID, Value FROM Blocks WHERE GroupID = ?`,
+	"add_swap_log":        `INSERT INTO Swaps(GroupID, MessageID, UserID, Keyword, StickerUID) VALUES(?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE UserID = VALUES(UserID), Keyword = VALUES(Keyword), StickerUID = VALUES(StickerUID)`,
+	"get_swap_log":        `SELECT UserID, Keyword, StickerUID FROM Swaps WHERE GroupID = ? AND MessageID = ?`,
+	"clean_swap_log":      `DELETE FROM Swaps WHERE Added < DATE_SUB(NOW(), INTERVAL 7 DAY)`,
+	"add_audit":           `INSERT INTO Audit(GroupID, UserID, Name, Setting, Old, New) VALUES(?, ?, ?, ?, ?, ?)`,
+	"list_audit":          `SELECT Name, Setting, COALESCE(Old, ""), COALESCE(New, ""), DATE_FORMAT(Changed, "%Y-%m-%d %H:%i") FROM Audit WHERE GroupID = ? ORDER BY AuditID DESC LIMIT 15`,
+	"clean_audit":         `DELETE FROM Audit WHERE Changed < DATE_SUB(NOW(), INTERVAL 90 DAY)`,
+	"set_opt_timezone":    `INSERT INTO Settings(GroupID, Timezone) VALUES(?, ?) ON DUPLICATE KEY UPDATE Timezone = VALUES(Timezone)`,
+	"set_opt_minimum":     `INSERT INTO Settings(GroupID, MinLength) VALUES(?, ?) ON DUPLICATE KEY UPDATE MinLength = VALUES(MinLength)`,
+	"set_opt_maximum":     `INSERT INTO Settings(GroupID, MaxLength) VALUES(?, ?) ON DUPLICATE KEY UPDATE MaxLength = VALUES(MaxLength)`,
+	"set_opt_silent":      `INSERT INTO Settings(GroupID, Silent) VALUES(?, ?) ON DUPLICATE KEY UPDATE Silent = VALUES(Silent)`,
+	"set_opt_reply":       `INSERT INTO Settings(GroupID, ReplyMode) VALUES(?, ?) ON DUPLICATE KEY UPDATE ReplyMode = VALUES(ReplyMode)`,
+	"add_schedule":        `INSERT INTO Schedules(GroupID, Start, End, Amount) VALUES(?, ?, ?, ?) ON DUPLICATE KEY UPDATE Amount = VALUES(Amount)`,
+	"del_schedule":        `DELETE FROM Schedules WHERE GroupID = ? AND Start = ? AND End = ?`,
+	"clear_schedule":      `DELETE FROM Schedules WHERE GroupID = ?`,
+	"list_schedule":       `SELECT Start, End, Amount FROM Schedules WHERE GroupID = ? ORDER BY Start`,
+	"list_schedules":      `SELECT Schedules.GroupID, Start, End, Amount, COALESCE(Settings.Timezone, "UTC") FROM Schedules LEFT JOIN Settings ON Settings.GroupID = Schedules.GroupID`,
+	"add_ban":             `INSERT IGNORE INTO Bans(ID) VALUES(?)`,
+	"del_ban":             `DELETE FROM Bans WHERE ID = ?`,
+	"list_bans":           `SELECT ID FROM Bans`,
+	"get_stats":           `SELECT (SELECT COUNT(*) FROM Mappings), (SELECT COUNT(DISTINCT UserID) FROM Mappings), (SELECT COUNT(*) FROM Users WHERE Started = TRUE), (SELECT COUNT(*) FROM Groups), (SELECT COUNT(*) FROM Settings)`,
 }
@@ -0,0 +1,270 @@
+// Copyright (C) 2021 - 2025 PurpleSec Team
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+
+package swapper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	telegram "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const defaultLanguage = "en"
+
+var english = catalog{
+	"help":                       helpMessage,
+	"help_admin":                 helpMessageAdmin,
+	"help_basic":                 helpMessageBasic,
+	"help_extra":                 helpMessageExtra,
+	"help_join":                  helpMessageJoin,
+	"error":                      errorMessage,
+	"error_admin":                errorMessageAdmin,
+	"add_done":                   `Sweet! I added the sticker to the swap word "{word}"!`,
+	"add_prompt":                 `OK! Send me a sticker to swap for "{word}"`,
+	"admin_only":                 "Sorry, but only group Admins can use that command.",
+	"admin_rights":               "Sorry, but you need the following Admin rights to use that command: {rights}",
+	"attribution":                "Swapped message from {user}",
+	"block_notice_sticker":       `An Admin of "{chat}" has blocked the sticker you swapped for "{word}" in that chat.`,
+	"block_notice_word":          `An Admin of "{chat}" has blocked your swap word "{word}" in that chat.`,
+	"block_reason":               "\n\nReason: {reason}",
+	"block_reply":                "Please reply to a swapped sticker (or its attribution message) with this command.",
+	"block_sticker":              `Sweet! That sticker won't be swapped in this chat anymore!`,
+	"block_unknown":              "Sorry, but I don't have a record of that swap (it may be too old).",
+	"block_word":                 `Sweet! That member's word "{word}" won't be swapped in this chat anymore!`,
+	"clear_confirm":              `Please reply with "confirm" in order to clear your list.`,
+	"clear_done":                 "Sweet! I've cleared your swap list!",
+	"command_add":                "Add a word to be swapped",
+	"command_clear":              "Remove all your swapped words",
+	"command_get":                "Get the sticker assigned to a word",
+	"command_help":               "More information about me",
+	"command_language":           "Show or change the language I use",
+	"command_list":               "List all your swapped words",
+	"command_remove":             "Remove a swapped word",
+	"command_swap_allowset":      "Only allow stickers from a sticker set",
+	"command_swap_animated":      "Swap in animated stickers",
+	"command_swap_blockset":      "Never swap stickers from a sticker set",
+	"command_swap_removeset":     "Remove a sticker set from the lists",
+	"command_swap_video":         "Swap in video stickers",
+	"command_swap_block":         "Reply to a swap to block it in this chat",
+	"command_swap_unblock":       "Reply to a swap to remove its block",
+	"command_swap_blockword":     "Never swap a word in this chat",
+	"command_swap_optin":         "Let me swap your messages in this chat",
+	"command_swap_optout":        "Stop me from swapping your messages in this chat",
+	"command_swap_unblockword":   "Remove a word from the blocked words",
+	"command_swap_attribution":   "Choose how I show who sent a swap",
+	"command_swap_autodelete":    "Delete attribution messages after some seconds",
+	"command_swap_captions":      "Swap photo and video captions",
+	"command_swap_delete":        "Delete the swapped message",
+	"command_swap_edits":         "Swap edited messages",
+	"command_swap_enable":        "Enable or disable swapping in this chat",
+	"command_swap_help":          "Show the Admin help message",
+	"command_swap_history":       "Show recent settings changes",
+	"command_swap_language":      "Set the language I use in this chat",
+	"command_swap_limit":         "Set the number of swaps allowed per timeout",
+	"command_swap_options":       "Show the settings for this chat",
+	"command_swap_maximum":       "Set the longest keyword I will swap",
+	"command_swap_minimum":       "Set the shortest keyword I will swap",
+	"command_swap_quiet":         "Add or remove a Quiet Hours window",
+	"command_swap_reply":         "Set which message swapped Stickers reply to",
+	"command_swap_silent":        "Enable or disable silent swaps",
+	"command_swap_template":      "Set the attribution message text",
+	"command_swap_timeout":       "Set the swap limit timeout in seconds",
+	"command_swap_timezone":      "Set the timezone used for Quiet Hours",
+	"command_swap_topic_enable":  "Enable or disable swapping in this topic",
+	"command_swap_topic_limit":   "Set the swap limit for this topic",
+	"command_swap_topic_reset":   "Remove the overrides for this topic",
+	"command_swap_topic_timeout": "Set the swap timeout for this topic",
+	"get_empty":                  `You don't have a sticker mapped for "{word}"!`,
+	"history_empty":              "No settings have been changed in this chat yet.",
+	"history_entry":              "\n{time} - {name} changed \"{setting}\" from \"{old}\" to \"{new}\"",
+	"history_header":             "Recent settings changes:\n",
+	"inline_add":                 "Click here to add some Stickers!",
+	"language_current":           "Your language is currently set to \"{language}\".\n\nI can speak: {languages}\nUse \"/language <code|reset>\" to change it.",
+	"language_invalid":           "Sorry, but I can't speak \"{language}\".\n\nI can speak: {languages}",
+	"language_reset":             "Sweet! I'll use your Telegram language from now on!",
+	"language_set":               `Sweet! I'll speak "{language}" from now on!`,
+	"maintenance":                "Sorry, I'm currently down for maintenance.\n\nPlease try again later.",
+	"length_invalid":             "Sorry, the shortest keyword length can't be longer than the longest! The current range is {minimum} - {maximum}.",
+	"list_empty":                 "You currently have no swapped words set.",
+	"list_header":                "You are currently swapping the words:\n",
+	"options":                    "I have the following settings:\n\nSwapping Enabled: {enabled}\nRemove Swapped: {remove}\nSwap Limit: {limit}\nSwap Timeout: {timeout} seconds.\nAttribution: {attribution}\nAttribution Auto-Delete: {expire} seconds.\nSwap Edits: {edits}\nSwap Captions: {captions}\nLanguage: {language}\nAnimated Stickers: {animated}\nVideo Stickers: {video}\nKeyword Length: {minimum} - {maximum}\nSilent Swaps: {silent}\nReply To: {reply}",
+	"options_words":              "\nBlocked Words: {words}",
+	"optin_done":                 "Sweet! I'll swap your messages in this chat again!",
+	"optout_anonymous":           "Sorry, I can't opt out anonymous Admins. Please send that command without staying anonymous.",
+	"optout_done":                "OK! I won't swap your messages in this chat anymore. Use \"/swap_optin\" to undo this.",
+	"options_allowsets":          "\nAllowed Sticker Sets: {sets}",
+	"options_blocksets":          "\nBlocked Sticker Sets: {sets}",
+	"options_schedule":           "\nTimezone: {timezone}\nQuiet Hours: {schedule}",
+	"options_schedule_active":    "\nActive Quiet Hours: {window}",
+	"options_template":           "\nAttribution Template: {template}",
+	"options_topic":              "\n\nThis topic has the following overrides:\n",
+	"options_topic_on":           "\nSwapping Enabled: {enabled}",
+	"options_topic_max":          "\nSwap Limit: {limit}",
+	"options_topic_time":         "\nSwap Timeout: {timeout} seconds.",
+	"panel_close":                "Close",
+	"panel_delete":               "Remove Swapped: {value}",
+	"panel_enable":               "Swapping: {value}",
+	"panel_limit":                "Limit: {value}",
+	"panel_timeout":              "Timeout: {value}s",
+	"quiet_added":                "Sweet! I've added the Quiet Hours window {window}!",
+	"quiet_cleared":              "Sweet! I've removed all the Quiet Hours windows!",
+	"quiet_removed":              "Sweet! I've removed the Quiet Hours window {window} (if it existed)!",
+	"remove_done":                `Sweet! I've removed the swap word "{word}" (if it existed)!`,
+	"remove_prompt":              "Please reply with the sticker you whish to delete from your swap list.",
+	"remove_sticker":             "Sweet! I've removed the swap word(s) associated with that sticker!",
+	"schedule_limit":             "{window} (limit {limit})",
+	"schedule_off":               "{window} (off)",
+	"sets_allowset":              `Sweet! Only allowed sticker sets (including "{set}") can be swapped now!`,
+	"sets_blockset":              `Sweet! I won't swap stickers from the "{set}" set!`,
+	"sets_removeset":             `Sweet! I've removed the "{set}" set from the sticker set lists!`,
+	"sticker_empty":              "You don't have that Sticker assigned to any swap words.\nUse the \"/add <word>\" command to add it!",
+	"sticker_header":             "That sticker is tied to the following word(s):\n",
+	"sticker_required":           "Sorry, but I require a Sticker.\n\nPlease invoke the previous command to try again.",
+	"template_length":            "Sorry, but the attribution template is limited to a max of 256 characters!",
+	"template_reset":             `Sweet! I've reset the "{option}" setting!`,
+	"timezone_invalid":           `Sorry, "{timezone}" is not a timezone I know! Try a name like "America/New_York" or "Europe/London".`,
+	"topic_only":                 "Sorry, but topic settings can only be changed inside a topic.",
+	"topic_reset":                "Sweet! This topic now uses the group settings!",
+	"topic_updated":              `Sweet! I've updated the "{option}" setting for this topic to "{value}"!`,
+	"unblock_sticker":            `Sweet! That sticker can be swapped in this chat again!`,
+	"unblock_word":               `Sweet! That member's word "{word}" can be swapped in this chat again!`,
+	"undo":                       "Undo",
+	"undo_denied":                "Sorry, only the sender can undo this swap.",
+	"undo_expired":               "Sorry, this swap can no longer be undone.",
+	"updated":                    `Sweet! I've updated the "{option}" setting to "{value}"!`,
+	"updated_seconds":            `Sweet! I've updated the "{option}" setting to {value} seconds!`,
+	"updated_swaps":              `Awesome! I've updated the "{option}" setting to {value} swaps!`,
+	"usage":                      "Sorry I don't recognize that option value.\n\nThe correct usage should be \"{usage}\"",
+	"words_blockword":            `Sweet! I won't swap the word "{word}" in this chat!`,
+	"words_unblockword":          `Sweet! I've unblocked the word "{word}" in this chat!`,
+	"word_length":                "Sorry, but swapped words must be at least 3 characters and limited to a max of 16 characters!",
+}
+
+type catalog map[string]string
+type languages map[string]catalog
+
+func loadLanguages(d string) (languages, error) {
+	l := languages{defaultLanguage: english}
+	if len(d) == 0 {
+		return l, nil
+	}
+	e, err := os.ReadDir(d)
+	if err != nil {
+		return nil, errors.New(`reading languages "` + d + `": ` + err.Error())
+	}
+	for i := range e {
+		if e[i].IsDir() || !strings.EqualFold(filepath.Ext(e[i].Name()), ".json") {
+			continue
+		}
+		var (
+			n = strings.ToLower(strings.TrimSuffix(e[i].Name(), filepath.Ext(e[i].Name())))
+			p = filepath.Join(d, e[i].Name())
+		)
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return nil, errors.New(`reading language "` + p + `": ` + err.Error())
+		}
+		var c catalog
+		if err = json.Unmarshal(b, &c); err != nil {
+			return nil, errors.New(`parsing language "` + p + `": ` + err.Error())
+		}
+		if len(n) > 8 {
+			return nil, errors.New(`language "` + p + `": invalid language code "` + n + `"`)
+		}
+		l[n] = c
+	}
+	return l, nil
+}
+func (l languages) list() string {
+	v := make([]string, 0, len(l))
+	for k := range l {
+		v = append(v, k)
+	}
+	sort.Strings(v)
+	return strings.Join(v, ", ")
+}
+func (l languages) match(v string) string {
+	if len(v) == 0 {
+		return ""
+	}
+	v = strings.ToLower(v)
+	if _, ok := l[v]; ok {
+		return v
+	}
+	if i := strings.IndexByte(v, '-'); i > 0 {
+		if _, ok := l[v[:i]]; ok {
+			return v[:i]
+		}
+	}
+	return ""
+}
+func (l languages) get(n, k string, r ...string) string {
+	v, ok := l[n][k]
+	if !ok {
+		v = english[k]
+	}
+	if len(r) == 0 {
+		return v
+	}
+	return strings.NewReplacer(r...).Replace(v)
+}
+func (c *container) text(s *Swapper, n, k string, r ...string) string {
+	return s.lang.get(n, k, append(r, c.brand...)...)
+}
+func (s *Swapper) language(x context.Context, u *telegram.User) string {
+	if u == nil {
+		return defaultLanguage
+	}
+	v, ok := s.users.get(u.ID)
+	if !ok {
+		r, err := s.sql.QueryContext(x, "get_user_lang", u.ID)
+		if err != nil {
+			s.logger(x).Error("Received an error when attempting to get the user language (UID: %d): %s!", u.ID, err.Error())
+			return s.lang.fallback(u.LanguageCode)
+		}
+		for r.Next() {
+			if err = r.Scan(&v); err != nil {
+				break
+			}
+		}
+		if r.Close(); err != nil {
+			s.logger(x).Error("Received an error when attempting to scan the user language (UID: %d): %s!", u.ID, err.Error())
+		}
+		s.users.set(u.ID, v)
+	}
+	if v = s.lang.match(v); len(v) > 0 {
+		return v
+	}
+	return s.lang.fallback(u.LanguageCode)
+}
+func (l languages) fallback(v string) string {
+	if v = l.match(v); len(v) > 0 {
+		return v
+	}
+	return defaultLanguage
+}
+func (s *Swapper) groupLanguage(x context.Context, g settings, u *telegram.User) string {
+	if v := s.lang.match(g.language); len(v) > 0 {
+		return v
+	}
+	return s.language(x, u)
+}
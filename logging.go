@@ -0,0 +1,176 @@
+// Copyright (C) 2021 - 2025 PurpleSec Team
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+
+package swapper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PurpleSec/logx"
+)
+
+type fields struct {
+	Bot     int64   `json:"bot_id,omitempty"`
+	Update  int     `json:"update_id,omitempty"`
+	Chat    int64   `json:"chat_id,omitempty"`
+	User    int64   `json:"user_id,omitempty"`
+	Command string  `json:"command,omitempty"`
+	Latency float64 `json:"latency_ms,omitempty"`
+}
+type line struct {
+	*fields
+	Time    string `json:"time"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+type output struct {
+	lock sync.Mutex
+	e    *json.Encoder
+	l, p logx.Level
+}
+type fieldsKey struct{}
+type structured struct {
+	o *output
+	f *fields
+}
+
+func newStructured(w io.Writer, l logx.Level) *structured {
+	return &structured{o: &output{e: json.NewEncoder(w), l: l, p: logx.Info}}
+}
+func (j *structured) with(f *fields) *structured {
+	return &structured{o: j.o, f: f}
+}
+func (j *structured) SetLevel(l logx.Level) {
+	j.o.lock.Lock()
+	j.o.l = l
+	j.o.lock.Unlock()
+}
+func (*structured) SetPrefix(_ string) {}
+func (j *structured) SetPrintLevel(l logx.Level) {
+	j.o.lock.Lock()
+	j.o.p = l
+	j.o.lock.Unlock()
+}
+func (j *structured) Print(v ...any) {
+	j.write(logx.Print, fmt.Sprint(v...))
+}
+func (j *structured) Println(v ...any) {
+	j.write(logx.Print, fmt.Sprint(v...))
+}
+func (j *structured) Panic(v ...any) {
+	m := fmt.Sprint(v...)
+	j.write(logx.Panic, m)
+	panic(m)
+}
+func (j *structured) Panicln(v ...any) {
+	j.Panic(v...)
+}
+func (j *structured) Panicf(m string, v ...any) {
+	j.Panic(fmt.Sprintf(m, v...))
+}
+func (j *structured) Printf(m string, v ...any) {
+	j.write(logx.Print, fmt.Sprintf(m, v...))
+}
+func (j *structured) Info(m string, v ...any) {
+	j.Log(logx.Info, 0, m, v...)
+}
+func (j *structured) Error(m string, v ...any) {
+	j.Log(logx.Error, 0, m, v...)
+}
+func (j *structured) Fatal(m string, v ...any) {
+	if j.Log(logx.Fatal, 0, m, v...); logx.FatalExits {
+		os.Exit(1)
+	}
+}
+func (j *structured) Trace(m string, v ...any) {
+	j.Log(logx.Trace, 0, m, v...)
+}
+func (j *structured) Debug(m string, v ...any) {
+	j.Log(logx.Debug, 0, m, v...)
+}
+func (j *structured) Warning(m string, v ...any) {
+	j.Log(logx.Warning, 0, m, v...)
+}
+func (j *structured) Log(l logx.Level, _ int, m string, v ...any) {
+	j.write(l, fmt.Sprintf(m, v...))
+}
+func (j *structured) write(l logx.Level, m string) {
+	j.o.lock.Lock()
+	if l == logx.Print {
+		l = j.o.p
+	}
+	if j.o.l <= l {
+		j.o.e.Encode(line{
+			fields:  j.f,
+			Time:    time.Now().Format(time.RFC3339Nano),
+			Level:   strings.TrimSpace(l.String()),
+			Message: m,
+		})
+	}
+	j.o.lock.Unlock()
+}
+func (s *Swapper) logger(x context.Context) logx.Log {
+	if s.json == nil {
+		return s.log
+	}
+	if f, ok := x.Value(fieldsKey{}).(*fields); ok {
+		return s.json.with(f)
+	}
+	return s.log
+}
+func newFields(c *container, n *update) *fields {
+	f := &fields{Bot: c.bot.Self.ID, Update: n.UpdateID}
+	m := n.Message
+	if m == nil {
+		m = n.EditedMessage
+	}
+	switch {
+	case m != nil:
+		if f.Chat = m.Chat.ID; m.From != nil {
+			f.User = m.From.ID
+		}
+		if v := strings.Fields(m.Text); len(v) > 0 && len(v[0]) > 1 && v[0][0] == '/' {
+			if f.Command = strings.ToLower(v[0][1:]); strings.IndexByte(f.Command, '@') > 0 {
+				f.Command = f.Command[:strings.IndexByte(f.Command, '@')]
+			}
+		}
+	case n.CallbackQuery != nil:
+		if f.User, f.Command = n.CallbackQuery.From.ID, n.CallbackQuery.Data; n.CallbackQuery.Message != nil && n.CallbackQuery.Message.Chat != nil {
+			f.Chat = n.CallbackQuery.Message.Chat.ID
+		}
+	case n.InlineQuery != nil:
+		f.User, f.Command = n.InlineQuery.From.ID, "inline"
+	case n.MyChatMember != nil:
+		f.Chat, f.User = n.MyChatMember.Chat.ID, n.MyChatMember.From.ID
+	case n.ChatMember != nil:
+		f.Chat, f.User = n.ChatMember.Chat.ID, n.ChatMember.From.ID
+	}
+	return f
+}
+func (s *Swapper) event(f *fields, d time.Duration) {
+	if f.Latency = float64(d.Microseconds()) / 1000; s.json != nil {
+		s.json.with(f).Info("Handled update.")
+		return
+	}
+	s.log.Trace("Handled update %d for bot %d (GID: %d, UID: %d, command: %s) in %s.", f.Update, f.Bot, f.Chat, f.User, f.Command, d)
+}
@@ -0,0 +1,101 @@
+// Copyright (C) 2021 - 2025 PurpleSec Team
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+
+package swapper
+
+import (
+	"context"
+
+	telegram "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const helpMessageJoin = `Hello there, I'm {bot_name}! Thanks for adding me!
+
+I'll swap out any messages that match the swap words that members have set with me.
+(Members can set their swap words by messaging me privately).
+
+` + helpMessageAdmin
+
+var updateTypes = []string{
+	telegram.UpdateTypeMessage,
+	telegram.UpdateTypeEditedMessage,
+	telegram.UpdateTypeInlineQuery,
+	telegram.UpdateTypeCallbackQuery,
+	telegram.UpdateTypeMyChatMember,
+	telegram.UpdateTypeChatMember,
+}
+
+func inChat(m *telegram.ChatMember) bool {
+	return m.IsCreator() || m.IsAdministrator() || m.Status == "member" || (m.Status == "restricted" && m.IsMember)
+}
+func (s *Swapper) leave(x context.Context, i int64) {
+	if _, err := s.sql.ExecContext(x, "del_group", i); err != nil {
+		s.logger(x).Error("Received an error when attempting to remove group data (GID: %d): %s!", i, err.Error())
+	}
+	s.invalidate(i)
+}
+func (c *container) joined(x context.Context, s *Swapper, m *telegram.ChatMemberUpdated, o chan<- telegram.Chattable) {
+	if m.Chat.IsPrivate() || m.NewChatMember.User == nil || m.NewChatMember.User.ID != c.bot.Self.ID {
+		return
+	}
+	if !inChat(&m.NewChatMember) {
+		s.logger(x).Debug("Removed from GID %d by %s, removing group data..", m.Chat.ID, m.From.String())
+		s.leave(x, m.Chat.ID)
+		return
+	}
+	d := m.NewChatMember.IsCreator() || (m.NewChatMember.IsAdministrator() && m.NewChatMember.CanDeleteMessages)
+	if _, err := s.sql.ExecContext(x, "add_group", m.Chat.ID, d); err != nil {
+		s.logger(x).Error("Received an error when attempting to record group data (GID: %d): %s!", m.Chat.ID, err.Error())
+	}
+	s.invalidate(m.Chat.ID)
+	if inChat(&m.OldChatMember) {
+		s.logger(x).Debug("Permissions changed in GID %d by %s (can delete: %t).", m.Chat.ID, m.From.String(), d)
+		return
+	}
+	s.logger(x).Debug("Added to GID %d by %s (can delete: %t).", m.Chat.ID, m.From.String(), d)
+	n := s.language(x, &m.From)
+	if g, err := s.group(x, m.Chat.ID); err == nil {
+		n = s.groupLanguage(x, g, &m.From)
+	}
+	o <- telegram.NewMessage(m.Chat.ID, c.text(s, n, "help_join"))
+}
+func (c *container) optout(x context.Context, s *Swapper, m *telegram.Message, e bool, o chan<- telegram.Chattable) {
+	q, n := "del_optout", s.language(x, m.From)
+	if e {
+		q = "add_optout"
+	}
+	if _, err := s.sql.ExecContext(x, q, m.Chat.ID, m.From.ID); err != nil {
+		s.logger(x).Error("Received an error when attempting to set the opt-out setting (GID: %d, UID: %d): %s!", m.Chat.ID, m.From.ID, err.Error())
+		sendResponse(o, m.Chat.ID, m.MessageID, c.text(s, n, "error"))
+		return
+	}
+	s.invalidate(m.Chat.ID)
+	s.logger(x).Trace(`User "%s" set opt-out to %t for GID %d!`, m.From.String(), e, m.Chat.ID)
+	if e {
+		sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "optout_done"))
+		return
+	}
+	sendResponse(o, m.Chat.ID, m.MessageID, s.lang.get(n, "optin_done"))
+}
+func (s *Swapper) migrate(x context.Context, o, n int64) {
+	if _, err := s.sql.ExecContext(x, "move_group", o, n); err != nil {
+		s.logger(x).Error("Received an error when attempting to migrate group data (GID: %d to %d): %s!", o, n, err.Error())
+		return
+	}
+	s.invalidate(o)
+	s.invalidate(n)
+	s.logger(x).Debug("Migrated group data from GID %d to %d.", o, n)
+}
@@ -0,0 +1,386 @@
+// Copyright (C) 2021 - 2025 PurpleSec Team
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+
+package swapper
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	telegram "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const helpMessageOwner = `Owner commands:
+
+/owner_help
+ - Show this help message.
+
+/owner_stats
+ - Show global usage stats.
+
+/owner_user <user id>
+ - Show the swaps and settings of a user.
+
+/owner_purge <user id>
+ - Remove all the swaps and settings of a user.
+
+/owner_groups
+ - List the most recent groups I'm in.
+
+/owner_group <group id>
+ - Show the settings of a group.
+
+/owner_set <group id> <enable|delete|edits|captions|animated|video|silent|limit|timeout|autodelete> <value>
+ - Change a setting of a group.
+
+/owner_ban <user or group id>
+ - Ban a user or group from using me.
+
+/owner_unban <user or group id>
+ - Remove a user or group from the ban list.
+
+/owner_bans
+ - List all banned users and groups.
+
+/owner_maintenance <true|false|1|0|yes|no>
+ - Pause swaps and commands for everyone except owners.
+
+/owner_broadcast <message>
+ - Send a message to every user that has started me or added a swap.`
+
+var ownerOptions = map[string]string{
+	"enable":     "set_opt_enable",
+	"delete":     "set_opt_delete",
+	"edits":      "set_opt_edits",
+	"captions":   "set_opt_captions",
+	"animated":   "set_opt_animated",
+	"video":      "set_opt_video",
+	"silent":     "set_opt_silent",
+	"limit":      "set_opt_limit",
+	"timeout":    "set_opt_timeout",
+	"autodelete": "set_opt_expire",
+}
+
+func (s *Swapper) isOwner(i int64) bool {
+	_, ok := s.owners[i]
+	return ok
+}
+func (s *Swapper) isPaused() bool {
+	s.lock.RLock()
+	v := s.paused
+	s.lock.RUnlock()
+	return v
+}
+func (s *Swapper) setPaused(v bool) {
+	s.lock.Lock()
+	s.paused = v
+	s.lock.Unlock()
+}
+func (s *Swapper) started(x context.Context, i int64) {
+	if _, err := s.sql.ExecContext(x, "add_user", i); err != nil {
+		s.logger(x).Error("Received an error when attempting to record a user (UID: %d): %s!", i, err.Error())
+	}
+}
+func (s *Swapper) stats(x context.Context) (string, error) {
+	r, err := s.sql.QueryContext(x, "get_stats")
+	if err != nil {
+		return "", err
+	}
+	var m, u, a, g, o uint64
+	for r.Next() {
+		if err = r.Scan(&m, &u, &a, &g, &o); err != nil {
+			break
+		}
+	}
+	if r.Close(); err != nil {
+		return "", err
+	}
+	return "Swaps: " + strconv.FormatUint(m, 10) + "\nUsers with Swaps: " + strconv.FormatUint(u, 10) +
+		"\nStarted Users: " + strconv.FormatUint(a, 10) + "\nGroups: " + strconv.FormatUint(g, 10) +
+		"\nCustomized Groups: " + strconv.FormatUint(o, 10) + "\nBots: " + strconv.Itoa(len(s.bots)) +
+		"\nMaintenance: " + strconv.FormatBool(s.isPaused()), nil
+}
+func (s *Swapper) user(x context.Context, i int64) (string, error) {
+	r, err := s.sql.QueryContext(x, "get_user", i, i, i)
+	if err != nil {
+		return "", err
+	}
+	var (
+		c uint64
+		l string
+		a bool
+	)
+	for r.Next() {
+		if err = r.Scan(&c, &l, &a); err != nil {
+			break
+		}
+	}
+	if r.Close(); err != nil {
+		return "", err
+	}
+	if len(l) == 0 {
+		l = "auto"
+	}
+	return "User " + strconv.FormatInt(i, 10) + ":\n\nSwaps: " + strconv.FormatUint(c, 10) +
+		"\nLanguage: " + l + "\nStarted: " + strconv.FormatBool(a), nil
+}
+func (s *Swapper) groupList(x context.Context) (string, error) {
+	r, err := s.sql.QueryContext(x, "list_groups")
+	if err != nil {
+		return "", err
+	}
+	var (
+		b    = builders.Get().(*strings.Builder)
+		i    int64
+		d, e bool
+	)
+	for b.WriteString("Groups (newest first):\n"); r.Next(); {
+		if err = r.Scan(&i, &d, &e); err != nil {
+			break
+		}
+		b.WriteString("\n" + strconv.FormatInt(i, 10) + " (enabled: " + strconv.FormatBool(e) + ", can delete: " + strconv.FormatBool(d) + ")")
+	}
+	r.Close()
+	v := b.String()
+	b.Reset()
+	builders.Put(b)
+	return v, err
+}
+func (s *Swapper) override(x context.Context, u *telegram.User, v string) (string, error) {
+	a := strings.Fields(v)
+	if len(a) != 3 {
+		return "", errors.New("usage: /owner_set <group id> <option> <value>")
+	}
+	i, err := strconv.ParseInt(a[0], 10, 64)
+	if err != nil {
+		return "", errors.New(`invalid group id "` + a[0] + `"`)
+	}
+	q, ok := ownerOptions[strings.ToLower(a[1])]
+	if !ok {
+		return "", errors.New(`unknown option "` + a[1] + `"`)
+	}
+	g, err := s.group(x, i)
+	if err != nil {
+		return "", err
+	}
+	var k any
+	switch q {
+	case "set_opt_enable", "set_opt_delete", "set_opt_edits", "set_opt_captions", "set_opt_animated", "set_opt_video", "set_opt_silent":
+		b, ok := parseBool(strings.ToLower(a[2]))
+		if !ok {
+			return "", errors.New(`invalid boolean "` + a[2] + `"`)
+		}
+		k = b
+	default:
+		n, err := strconv.ParseUint(a[2], 10, 16)
+		if err != nil {
+			return "", errors.New(`invalid number "` + a[2] + `"`)
+		}
+		k = n
+	}
+	if _, err = s.sql.ExecContext(x, q, i, k); err != nil {
+		return "", err
+	}
+	s.invalidate(i)
+	s.change(x, i, u.ID, u.String(), "swap_"+strings.ToLower(a[1]), g.value("swap_"+strings.ToLower(a[1]), 0), a[2])
+	return `Updated the "` + strings.ToLower(a[1]) + `" setting of GID ` + a[0] + ` to "` + a[2] + `".`, nil
+}
+func (c *container) owner(x context.Context, s *Swapper, m *telegram.Message, o chan<- telegram.Chattable) bool {
+	if len(m.Text) < 7 || !stringMatchIndex(7, m.Text, "/owner_") || !s.isOwner(m.From.ID) {
+		return false
+	}
+	var (
+		l = strings.TrimSpace(m.Text[7:])
+		v string
+	)
+	if d := strings.IndexByte(l, ' '); d > 0 {
+		l, v = l[:d], strings.TrimSpace(l[d+1:])
+	}
+	s.logger(x).Info(`Owner "%s" issued the command "owner_%s".`, m.From.String(), l)
+	switch strings.ToLower(l) {
+	case "stats":
+		r, err := s.stats(x)
+		if err != nil {
+			s.logger(x).Error("Received an error when attempting to get stats: %s!", err.Error())
+			r = "Error: " + err.Error()
+		}
+		o <- telegram.NewMessage(m.Chat.ID, r)
+	case "user", "purge":
+		i, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			o <- telegram.NewMessage(m.Chat.ID, `Invalid user id "`+v+`"!`)
+			return true
+		}
+		if strings.EqualFold(l, "user") {
+			r, err := s.user(x, i)
+			if err != nil {
+				s.logger(x).Error("Received an error when attempting to get user info (UID: %d): %s!", i, err.Error())
+				r = "Error: " + err.Error()
+			}
+			o <- telegram.NewMessage(m.Chat.ID, r)
+			return true
+		}
+		if _, err = s.sql.ExecContext(x, "del_user", i); err != nil {
+			s.logger(x).Error("Received an error when attempting to purge a user (UID: %d): %s!", i, err.Error())
+			o <- telegram.NewMessage(m.Chat.ID, "Error: "+err.Error())
+			return true
+		}
+		s.words.drop(i)
+		s.users.delete(i)
+		s.groups.clear()
+		s.clearUser(i)
+		o <- telegram.NewMessage(m.Chat.ID, "Purged all data for UID "+v+".")
+	case "groups":
+		r, err := s.groupList(x)
+		if err != nil {
+			s.logger(x).Error("Received an error when attempting to list groups: %s!", err.Error())
+			r = "Error: " + err.Error()
+		}
+		o <- telegram.NewMessage(m.Chat.ID, r)
+	case "group":
+		i, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			o <- telegram.NewMessage(m.Chat.ID, `Invalid group id "`+v+`"!`)
+			return true
+		}
+		g, err := s.group(x, i)
+		if err != nil {
+			s.logger(x).Error("Received an error when attempting to get group settings (GID: %d): %s!", i, err.Error())
+			o <- telegram.NewMessage(m.Chat.ID, "Error: "+err.Error())
+			return true
+		}
+		o <- telegram.NewMessage(m.Chat.ID, "GID "+v+" (can delete: "+strconv.FormatBool(g.deletable)+")\n\n"+s.options(s.lang.fallback(g.language), g, i, 0))
+	case "set":
+		r, err := s.override(x, m.From, v)
+		if err != nil {
+			r = "Error: " + err.Error()
+		}
+		o <- telegram.NewMessage(m.Chat.ID, r)
+	case "ban", "unban":
+		i, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || i == 0 {
+			o <- telegram.NewMessage(m.Chat.ID, `Invalid user or group id "`+v+`"!`)
+			return true
+		}
+		if strings.EqualFold(l, "unban") {
+			if _, err = s.sql.ExecContext(x, "del_ban", i); err != nil {
+				s.logger(x).Error("Received an error when attempting to remove a ban (ID: %d): %s!", i, err.Error())
+				o <- telegram.NewMessage(m.Chat.ID, "Error: "+err.Error())
+				return true
+			}
+			s.bans.remove(i)
+			o <- telegram.NewMessage(m.Chat.ID, "Removed the ban for ID "+v+".")
+			return true
+		}
+		if _, err = s.sql.ExecContext(x, "add_ban", i); err != nil {
+			s.logger(x).Error("Received an error when attempting to add a ban (ID: %d): %s!", i, err.Error())
+			o <- telegram.NewMessage(m.Chat.ID, "Error: "+err.Error())
+			return true
+		}
+		if s.bans.add(i); i > 0 {
+			s.clearUser(i)
+		} else if s.evict {
+			for _, b := range s.bots {
+				b.depart(x, s, i)
+			}
+		}
+		o <- telegram.NewMessage(m.Chat.ID, "Banned ID "+v+".")
+	case "bans":
+		b := builders.Get().(*strings.Builder)
+		b.WriteString("Banned IDs:\n")
+		for _, i := range s.bans.list() {
+			b.WriteString("\n" + strconv.FormatInt(i, 10))
+		}
+		o <- telegram.NewMessage(m.Chat.ID, b.String())
+		b.Reset()
+		builders.Put(b)
+	case "maintenance":
+		e, ok := parseBool(strings.ToLower(v))
+		if !ok {
+			o <- telegram.NewMessage(m.Chat.ID, "Maintenance mode is "+strconv.FormatBool(s.isPaused())+".")
+			return true
+		}
+		s.setPaused(e)
+		s.logger(x).Info(`Owner "%s" set maintenance mode to "%t".`, m.From.String(), e)
+		o <- telegram.NewMessage(m.Chat.ID, "Maintenance mode is now "+strconv.FormatBool(e)+".")
+	case "broadcast":
+		if len(v) == 0 {
+			o <- telegram.NewMessage(m.Chat.ID, "Usage: /owner_broadcast <message>")
+			return true
+		}
+		c.broadcast(s, m.Chat.ID, v)
+		o <- telegram.NewMessage(m.Chat.ID, "Broadcast started, I'll let you know when it's done.")
+	default:
+		o <- telegram.NewMessage(m.Chat.ID, helpMessageOwner)
+	}
+	return true
+}
+func (c *container) broadcast(s *Swapper, i int64, v string) {
+	c.g.Add(1)
+	go func() {
+		defer c.g.Done()
+		r, err := s.sql.QueryContext(c.x, "list_users")
+		if err != nil {
+			s.log.Error("Received an error when attempting to list users for a broadcast: %s!", err.Error())
+			return
+		}
+		var (
+			u []int64
+			k int64
+		)
+		for r.Next() {
+			if err = r.Scan(&k); err != nil {
+				break
+			}
+			u = append(u, k)
+		}
+		if r.Close(); err != nil {
+			s.log.Error("Received an error when attempting to scan users for a broadcast: %s!", err.Error())
+			return
+		}
+		var (
+			t    = time.NewTicker(time.Second / 20)
+			n, f int
+		)
+		defer t.Stop()
+		for _, k = range u {
+			select {
+			case <-c.x.Done():
+				s.log.Warning("Broadcast stopped early after %d of %d users.", n+f, len(u))
+				return
+			case <-t.C:
+			}
+			if _, err = c.bot.Send(telegram.NewMessage(k, v)); err == nil {
+				n++
+				continue
+			}
+			f++
+			s.log.Debug("Received an error attempting to broadcast to UID %d: %s", k, err.Error())
+			if e, ok := err.(*telegram.Error); ok && e.Code == 403 {
+				if _, err = s.sql.ExecContext(c.x, "stop_user", k); err != nil {
+					s.log.Error("Received an error when attempting to update a user (UID: %d): %s!", k, err.Error())
+				}
+			}
+		}
+		s.log.Info("Broadcast finished, sent to %d users (%d failed).", n, f)
+		if _, err = c.bot.Send(telegram.NewMessage(i, "Broadcast finished, sent to "+strconv.Itoa(n)+" users ("+strconv.Itoa(f)+" failed).")); err != nil {
+			s.log.Error("Error sending Telegram message to UID %d: %s!", i, err.Error())
+		}
+	}()
+}
@@ -0,0 +1,154 @@
+// Copyright (C) 2021 - 2025 PurpleSec Team
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+
+package swapper
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	telegram "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const panelPrefix = "opt:"
+
+var panelCommands = map[string]string{
+	"enable":   "swap_enable",
+	"delete":   "swap_delete",
+	"limit+":   "swap_limit",
+	"limit-":   "swap_limit",
+	"timeout+": "swap_timeout",
+	"timeout-": "swap_timeout",
+}
+
+func panelButton(v, a string, t int) telegram.InlineKeyboardButton {
+	return telegram.NewInlineKeyboardButtonData(v, panelPrefix+a+":"+strconv.Itoa(t))
+}
+func (s *Swapper) panel(n string, g settings, t int) telegram.InlineKeyboardMarkup {
+	return telegram.NewInlineKeyboardMarkup(
+		telegram.NewInlineKeyboardRow(
+			panelButton(s.lang.get(n, "panel_enable", "{value}", strconv.FormatBool(g.enabled)), "enable", t),
+			panelButton(s.lang.get(n, "panel_delete", "{value}", strconv.FormatBool(g.remove)), "delete", t),
+		),
+		telegram.NewInlineKeyboardRow(
+			panelButton("-", "limit-", t),
+			panelButton(s.lang.get(n, "panel_limit", "{value}", strconv.Itoa(int(g.amount))), "none", t),
+			panelButton("+", "limit+", t),
+		),
+		telegram.NewInlineKeyboardRow(
+			panelButton("-", "timeout-", t),
+			panelButton(s.lang.get(n, "panel_timeout", "{value}", strconv.Itoa(int(g.timeout))), "none", t),
+			panelButton("+", "timeout+", t),
+		),
+		telegram.NewInlineKeyboardRow(panelButton(s.lang.get(n, "panel_close"), "close", t)),
+	)
+}
+func step(v uint16, d int) uint16 {
+	if d < 0 && int(v) < -d {
+		return 0
+	}
+	if d > 0 && int(v)+d > 0xFFFF {
+		return 0xFFFF
+	}
+	return uint16(int(v) + d)
+}
+func (c *container) configure(x context.Context, s *Swapper, q *telegram.CallbackQuery) {
+	a := strings.Split(q.Data[len(panelPrefix):], ":")
+	if len(a) != 2 {
+		c.answer(s, q.ID, "")
+		return
+	}
+	t, err := strconv.Atoi(a[1])
+	if err != nil {
+		c.answer(s, q.ID, "")
+		return
+	}
+	i := q.Message.Chat.ID
+	u, err := c.admin(x, s, i, q.From.ID)
+	if err != nil {
+		s.logger(x).Error("Received an error during ChatMember lookup (GID: %d, UID: %d): %s!", i, q.From.ID, err.Error())
+		c.answer(s, q.ID, c.text(s, s.language(x, q.From), "error_admin"))
+		return
+	}
+	if u.Status != "administrator" && u.Status != "creator" {
+		s.logger(x).Debug(`Non-admin user "%s" attempted to use the settings panel in GID %d!`, q.From.String(), i)
+		c.answer(s, q.ID, s.lang.get(s.language(x, q.From), "admin_only"))
+		return
+	}
+	if a[0] == "none" {
+		c.answer(s, q.ID, "")
+		return
+	}
+	if a[0] == "close" {
+		c.answer(s, q.ID, "")
+		if _, err = c.bot.Request(telegram.NewDeleteMessage(i, q.Message.MessageID)); err != nil {
+			s.logger(x).Warning("Received an error attempting to delete the settings panel from GID %d: %s", i, err.Error())
+		}
+		return
+	}
+	k, ok := panelCommands[a[0]]
+	if !ok {
+		c.answer(s, q.ID, "")
+		return
+	}
+	if r := s.rights.missing(k, &u); len(r) > 0 {
+		s.logger(x).Debug(`Admin "%s" is missing the rights %v for "%s" in GID %d!`, q.From.String(), r, k, i)
+		c.answer(s, q.ID, s.lang.get(s.language(x, q.From), "admin_rights", "{rights}", strings.Join(r, ", ")))
+		return
+	}
+	g, err := s.group(x, i)
+	if err != nil {
+		s.logger(x).Error("Received an error when attempting to get group settings (GID: %d): %s!", i, err.Error())
+		c.answer(s, q.ID, c.text(s, s.language(x, q.From), "error_admin"))
+		return
+	}
+	var v any
+	switch a[0] {
+	case "enable":
+		v = !g.enabled
+	case "delete":
+		v = !g.remove
+	case "limit+":
+		v = step(g.amount, 1)
+	case "limit-":
+		v = step(g.amount, -1)
+	case "timeout+":
+		v = step(g.timeout, 5)
+	case "timeout-":
+		v = step(g.timeout, -5)
+	}
+	if _, err = s.sql.ExecContext(x, "set_opt_"+k[5:], i, v); err != nil {
+		s.logger(x).Error("Received an error when attempting to set the %s setting (GID: %d): %s!", k[5:], i, err.Error())
+		c.answer(s, q.ID, c.text(s, s.language(x, q.From), "error_admin"))
+		return
+	}
+	o := g.value(k, t)
+	s.invalidate(i)
+	if g, err = s.group(x, i); err != nil {
+		s.logger(x).Error("Received an error when attempting to get group settings (GID: %d): %s!", i, err.Error())
+		c.answer(s, q.ID, c.text(s, s.language(x, q.From), "error_admin"))
+		return
+	}
+	s.change(x, i, q.From.ID, q.From.String(), k, o, g.value(k, t))
+	s.logger(x).Trace(`Admin "%s" set the "%s" to "%s" setting for GID %d!`, q.From.String(), k, g.value(k, t), i)
+	c.answer(s, q.ID, "")
+	n := s.groupLanguage(x, g, q.From)
+	e := telegram.NewEditMessageTextAndMarkup(i, q.Message.MessageID, s.options(n, g, i, t), s.panel(n, g, t))
+	if _, err = c.bot.Request(e); err != nil {
+		s.logger(x).Warning("Received an error attempting to update the settings panel in GID %d: %s", i, err.Error())
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright (C) 2021 - 2025 PurpleSec Team
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+
+package swapper
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	// Bundle the timezone database so group timezones work without system zoneinfo.
+	_ "time/tzdata"
+)
+
+type window struct {
+	amount     sql.NullInt32
+	start, end uint16
+}
+
+func minutes(v string) (uint16, bool) {
+	if len(v) != 5 || v[2] != ':' {
+		return 0, false
+	}
+	h, err := strconv.ParseUint(v[0:2], 10, 8)
+	if err != nil || h > 23 {
+		return 0, false
+	}
+	m, err := strconv.ParseUint(v[3:5], 10, 8)
+	if err != nil || m > 59 {
+		return 0, false
+	}
+	return uint16(h*60 + m), true
+}
+func parseWindow(v string) (window, bool) {
+	i := strings.IndexByte(v, '-')
+	if i == -1 {
+		return window{}, false
+	}
+	a, ok := minutes(v[:i])
+	if !ok {
+		return window{}, false
+	}
+	b, ok := minutes(v[i+1:])
+	if !ok || a == b {
+		return window{}, false
+	}
+	return window{start: a, end: b}, true
+}
+func (w window) active(t time.Time) bool {
+	n := uint16(t.Hour()*60 + t.Minute())
+	if w.start < w.end {
+		return n >= w.start && n < w.end
+	}
+	return n >= w.start || n < w.end
+}
+func (w window) stricter(o window) bool {
+	if !w.amount.Valid {
+		return o.amount.Valid
+	}
+	return o.amount.Valid && w.amount.Int32 < o.amount.Int32
+}
+func (w window) String() string {
+	return clock(w.start) + "-" + clock(w.end)
+}
+func clock(v uint16) string {
+	h, m := strconv.Itoa(int(v/60)), strconv.Itoa(int(v%60))
+	if len(h) == 1 {
+		h = "0" + h
+	}
+	if len(m) == 1 {
+		m = "0" + m
+	}
+	return h + ":" + m
+}
+func (s *Swapper) quiet(i int64) (window, bool) {
+	s.lock.RLock()
+	w, ok := s.windows[i]
+	s.lock.RUnlock()
+	return w, ok
+}
+func (s *Swapper) schedule(x context.Context) {
+	r, err := s.sql.QueryContext(x, "list_schedules")
+	if err != nil {
+		s.logger(x).Error("Received an error when attempting to get the group schedules: %s!", err.Error())
+		return
+	}
+	var (
+		q = make(map[int64]window)
+		z = make(map[string]*time.Location)
+		n = time.Now()
+	)
+	for r.Next() {
+		var (
+			i int64
+			w window
+			k string
+		)
+		if err = r.Scan(&i, &w.start, &w.end, &w.amount, &k); err != nil {
+			break
+		}
+		l, ok := z[k]
+		if !ok {
+			if l, err = time.LoadLocation(k); err != nil {
+				s.logger(x).Warning(`Group GID %d has an invalid timezone "%s", using UTC: %s!`, i, k, err.Error())
+				l, err = time.UTC, nil
+			}
+			z[k] = l
+		}
+		if !w.active(n.In(l)) {
+			continue
+		}
+		if v, ok := q[i]; !ok || w.stricter(v) {
+			q[i] = w
+		}
+	}
+	if r.Close(); err != nil {
+		s.logger(x).Error("Received an error when attempting to scan the group schedules: %s!", err.Error())
+		return
+	}
+	s.lock.Lock()
+	for i, w := range q {
+		if v, ok := s.windows[i]; !ok || v != w {
+			s.logger(x).Debug("Schedule %s is now active for GID %d.", w.String(), i)
+		}
+	}
+	for i, w := range s.windows {
+		if _, ok := q[i]; !ok {
+			s.logger(x).Debug("Schedule %s has ended for GID %d.", w.String(), i)
+		}
+	}
+	s.windows = q
+	s.lock.Unlock()
+}
@@ -21,12 +21,13 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
-	"net/http"
+	"io"
 	"os"
 	"os/signal"
 	"strconv"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/PurpleSec/logx"
 	"github.com/PurpleSec/mapper"
@@ -40,23 +41,42 @@ import (
 // Use the 'NewSwapper' function to properly create a Swapper.
 type Swapper struct {
 	log     logx.Log
+	json    *structured
 	sql     *mapper.Map
 	add     map[int64]string
 	del     map[int64]struct{}
 	lock    sync.RWMutex
 	cancel  context.CancelFunc
-	limits  map[int64]*limit
+	limits  map[topic]*limit
+	lang    languages
+	words   index
+	bans    banlist
+	users   *expiring[int64, string]
+	groups  *expiring[int64, settings]
+	undos   *expiring[message, *undo]
+	admins  *expiring[member, telegram.ChatMember]
+	owners  map[int64]struct{}
+	windows map[int64]window
 	confirm map[int64]struct{}
 	bots    []*container
+	rights  rights
+	timeout time.Duration
+	workers int
+	paused  bool
+	evict   bool
 }
 type container struct {
-	ch  chan telegram.Chattable
-	bot *telegram.BotAPI
+	x     context.Context
+	g     *sync.WaitGroup
+	ch    chan telegram.Chattable
+	bot   *telegram.BotAPI
+	done  chan struct{}
+	brand []string
 }
 
 func (c *container) stop() {
-	c.bot.StopReceivingUpdates()
 	close(c.ch)
+	<-c.done
 	c.bot, c.ch = nil, nil
 }
 
@@ -67,6 +87,8 @@ func (c *container) stop() {
 func (s *Swapper) Run() error {
 	var (
 		o = make(chan os.Signal, 1)
+		t = time.NewTicker(time.Minute)
+		c = time.NewTicker(time.Hour)
 		x context.Context
 		g sync.WaitGroup
 	)
@@ -77,22 +99,33 @@ func (s *Swapper) Run() error {
 		s.log.Debug("Starting bot %d..", i)
 		s.bots[i].start(x, s, &g)
 	}
-	for {
+	s.schedule(x)
+	for s.cleanup(x); ; {
 		select {
 		case <-o:
 			goto cleanup
+		case <-t.C:
+			s.groups.prune()
+			s.undos.prune()
+			s.users.prune()
+			s.admins.prune()
+			s.schedule(x)
+		case <-c.C:
+			s.cleanup(x)
 		case <-x.Done():
 			goto cleanup
 		}
 	}
 cleanup:
+	t.Stop()
+	c.Stop()
 	signal.Stop(o)
 	s.cancel()
+	g.Wait()
 	for i := range s.bots {
 		s.log.Debug("Stopping bot %d..", i)
 		s.bots[i].stop()
 	}
-	g.Wait()
 	close(o)
 	return s.sql.Close()
 }
@@ -112,45 +145,44 @@ func New(s string, empty bool) (*Swapper, error) {
 	if err = c.check(); err != nil {
 		return nil, err
 	}
-	l := logx.Multiple(logx.Console(logx.Level(c.Log.Level)))
-	if len(c.Log.File) > 0 {
-		f, err2 := logx.File(c.Log.File, logx.Append, logx.Level(c.Log.Level))
-		if err2 != nil {
-			return nil, errors.New(`log file "` + c.Log.File + `": ` + err2.Error())
-		}
-		l.Add(f)
-	}
-	z := make([]*container, 1, 4)
-	if k := len(c.Telegram.e); k > 0 {
-		z = append(z, make([]*container, k-1)...)
-		for i := range c.Telegram.e {
-			b, err := telegram.NewBotAPIWithClient(c.Telegram.e[i], "https://api.telegram.org/bot%s/%s", &http.Client{
-				Transport: &http.Transport{
-					Proxy:             http.ProxyFromEnvironment,
-					MaxIdleConns:      256,
-					ForceAttemptHTTP2: false,
-				},
-			})
-			if err != nil {
-				return nil, errors.New("telegram key (" + strconv.Itoa(i) + ") login: " + err.Error())
+	var (
+		l logx.Log
+		y *structured
+	)
+	if c.Log.Format == "json" {
+		w := io.Writer(logx.DefaultConsole)
+		if len(c.Log.File) > 0 {
+			f, err2 := os.OpenFile(c.Log.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640)
+			if err2 != nil {
+				return nil, errors.New(`log file "` + c.Log.File + `": ` + err2.Error())
 			}
-			z[i] = &container{bot: b}
+			w = io.MultiWriter(w, f)
 		}
+		y = newStructured(w, logx.Level(c.Log.Level))
+		l = y
 	} else {
-		b, err := telegram.NewBotAPIWithClient(c.Telegram.s, "https://api.telegram.org/bot%s/%s", &http.Client{
-			Transport: &http.Transport{
-				Proxy:             http.ProxyFromEnvironment,
-				MaxIdleConns:      256,
-				ForceAttemptHTTP2: false,
-			},
-		})
-		if err != nil {
-			return nil, errors.New("telegram login: " + err.Error())
+		m := logx.Multiple(logx.Console(logx.Level(c.Log.Level)))
+		if len(c.Log.File) > 0 {
+			f, err2 := logx.File(c.Log.File, logx.Append, logx.Level(c.Log.Level))
+			if err2 != nil {
+				return nil, errors.New(`log file "` + c.Log.File + `": ` + err2.Error())
+			}
+			m.Add(f)
 		}
-		z[0] = &container{bot: b}
+		l = m
 	}
-	if len(z) == 1 && z[0] == nil {
-		return nil, errors.New("no telegram accounts")
+	z := make([]*container, len(c.Telegram))
+	for i := range c.Telegram {
+		a := c.API.merge(c.Telegram[i].API)
+		h, err := a.client()
+		if err != nil {
+			return nil, errors.New("telegram key (" + strconv.Itoa(i) + ") client: " + err.Error())
+		}
+		b, err := telegram.NewBotAPIWithClient(c.Telegram[i].Key, a.URL, h)
+		if err != nil {
+			return nil, errors.New("telegram key (" + strconv.Itoa(i) + ") login: " + err.Error())
+		}
+		z[i] = &container{bot: b, brand: c.Brand.merge(c.Telegram[i].Brand).replacer(b.Self.UserName, b.Self.FirstName)}
 	}
 	d, err := sql.Open(
 		"mysql",
@@ -177,19 +209,53 @@ func New(s string, empty bool) (*Swapper, error) {
 		m.Close()
 		return nil, errors.New("database schema: " + err.Error())
 	}
-	return &Swapper{
+	g, err := loadLanguages(c.Language)
+	if err != nil {
+		m.Close()
+		return nil, err
+	}
+	r := &Swapper{
 		sql:     m,
 		log:     l,
+		json:    y,
 		add:     make(map[int64]string),
 		del:     make(map[int64]struct{}),
 		bots:    z,
-		limits:  make(map[int64]*limit),
+		lang:    g,
+		users:   newExpiring[int64, string](c.Cache.Users),
+		bans:    banlist{m: make(map[int64]struct{})},
+		words:   index{m: make(map[int64]map[string]struct{})},
+		limits:  make(map[topic]*limit),
+		groups:  newExpiring[int64, settings](c.Cache.Groups),
+		undos:   newExpiring[message, *undo](undoWindow * 2),
+		admins:  newExpiring[member, telegram.ChatMember](c.Cache.Admins),
+		owners:  make(map[int64]struct{}, len(c.Owners)),
 		confirm: make(map[int64]struct{}),
-	}, nil
+		timeout: c.Timeout,
+		workers: c.Workers,
+		evict:   c.BanLeave,
+		rights:  c.Rights,
+	}
+	for _, v := range c.Owners {
+		r.owners[v] = confirm
+	}
+	if err = r.words.load(context.Background(), m); err != nil {
+		m.Close()
+		return nil, errors.New("loading keywords: " + err.Error())
+	}
+	if err = r.bans.load(context.Background(), m); err != nil {
+		m.Close()
+		return nil, errors.New("loading bans: " + err.Error())
+	}
+	return r, nil
 }
 func (c *container) start(x context.Context, s *Swapper, g *sync.WaitGroup) {
-	r := c.bot.GetUpdatesChan(telegram.UpdateConfig{})
-	c.ch = make(chan telegram.Chattable, 128)
-	go c.send(x, s, g, c.ch)
+	r := make(chan update, 128)
+	c.x, c.g = x, g
+	c.ch, c.done = make(chan telegram.Chattable, 128), make(chan struct{})
+	c.register(s)
+	go c.poll(x, s, r)
+	go c.send(s, c.ch)
+	g.Add(1)
 	go c.receive(x, s, g, c.ch, r)
 }
@@ -19,6 +19,7 @@ package swapper
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"strconv"
 	"strings"
 	"sync"
@@ -27,15 +28,40 @@ import (
 	telegram "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-func (s *Swapper) check(i int64) bool {
-	l, ok := s.limits[i]
-	if !ok {
+type update struct {
+	telegram.Update
+	thread int
+	video  bool
+}
+type thread struct {
+	Sticker *struct {
+		Video bool `json:"is_video"`
+	} `json:"sticker"`
+	Thread int  `json:"message_thread_id"`
+	Topic  bool `json:"is_topic_message"`
+}
+type threads struct {
+	Message       *thread `json:"message"`
+	EditedMessage *thread `json:"edited_message"`
+}
+
+func threadParams(i int64, t int) telegram.Params {
+	p := telegram.Params{"chat_id": strconv.FormatInt(i, 10)}
+	p.AddNonZero("message_thread_id", t)
+	return p
+}
+func (s *Swapper) check(k topic, a, t uint16) bool {
+	if a == 0 {
 		return true
 	}
-	if l.max == 0 {
-		return true
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	l, ok := s.limits[k]
+	if !ok {
+		l = new(limit)
+		s.limits[k] = l
 	}
-	if time.Now().After(l.free) {
+	if l.gap, l.max = time.Duration(t)*time.Second, a; time.Now().After(l.free) {
 		l.count, l.free = 1, time.Now().Add(l.gap)
 		return true
 	}
@@ -45,19 +71,18 @@ func (s *Swapper) check(i int64) bool {
 	l.count++
 	return true
 }
-func (s *Swapper) update(i int64, a, t uint16) {
-	l, ok := s.limits[i]
-	if !ok {
-		l = new(limit)
-		s.limits[i] = l
-	}
-	l.gap, l.max = time.Duration(t)*time.Second, a
-	if time.Now().After(l.free) {
-		l.count, l.free = 0, time.Now().Add(l.gap)
+func (s *Swapper) invalidate(i int64) {
+	s.lock.Lock()
+	for k := range s.limits {
+		if k.chat == i {
+			delete(s.limits, k)
+		}
 	}
+	s.lock.Unlock()
+	s.groups.delete(i)
 }
 func (s *Swapper) inline(x context.Context, m *telegram.InlineQuery) []any {
-	if len(m.Query) < 1 || len(m.Query) > 16 {
+	if len(m.Query) < 1 || len(m.Query) > keywordMax || s.isPaused() || s.bans.has(m.From.ID) {
 		return nil
 	}
 	var (
@@ -70,7 +95,7 @@ func (s *Swapper) inline(x context.Context, m *telegram.InlineQuery) []any {
 		r, err = s.sql.QueryContext(x, "inline", m.From.ID, strings.TrimSpace(m.Query)+"%")
 	}
 	if err != nil {
-		s.log.Error("Received an error attempting to get the inline sticker value for UID: %d: %s!", m.From.ID, err.Error())
+		s.logger(x).Error("Received an error attempting to get the inline sticker value for UID: %d: %s!", m.From.ID, err.Error())
 		return nil
 	}
 	var (
@@ -84,118 +109,632 @@ func (s *Swapper) inline(x context.Context, m *telegram.InlineQuery) []any {
 		o = append(o, telegram.NewInlineQueryResultCachedSticker(m.ID+"res"+strconv.Itoa(i), v, ""))
 	}
 	if r.Close(); err != nil {
-		s.log.Error("Received an error attempting to scan the inline sticker value for UID: %d: %s!", m.From.ID, err.Error())
+		s.logger(x).Error("Received an error attempting to scan the inline sticker value for UID: %d: %s!", m.From.ID, err.Error())
 		return nil
 	}
 	if len(o) == 0 {
 		return nil
 	}
-	s.log.Trace(`Found an inline swap match "%s" by %s!`, v, m.From.String())
+	s.logger(x).Trace(`Found an inline swap match "%s" by %s!`, v, m.From.String())
 	return o
 }
-func (c *container) send(x context.Context, s *Swapper, g *sync.WaitGroup, o <-chan telegram.Chattable) {
+func (c *container) send(s *Swapper, o <-chan telegram.Chattable) {
 	s.log.Debug("Starting Telegram sender thread..")
-	for g.Add(1); ; {
-		select {
-		case n := <-o:
-			if _, err := c.bot.Send(n); err != nil {
-				s.log.Error(`Error sending Telegram message to chat: %s!`, err.Error())
-			}
-		case <-x.Done():
-			s.log.Debug("Stopping Telegram sender thread.")
-			g.Done()
-			return
+	for n := range o {
+		if _, err := c.bot.Send(n); err != nil {
+			s.log.Error(`Error sending Telegram message to chat: %s!`, err.Error())
 		}
 	}
+	s.log.Debug("Stopping Telegram sender thread.")
+	close(c.done)
 }
-func (c *container) swap(x context.Context, s *Swapper, m *telegram.Message, o chan<- telegram.Chattable) {
-	if m.From.IsBot || len(m.Text) == 0 || len(m.Text) < 3 || len(m.Text) > 16 || m.Text[0] == '/' || m.Text[0] < 33 {
+func (s *Swapper) group(x context.Context, i int64) (settings, error) {
+	if g, ok := s.groups.get(i); ok {
+		return g, nil
+	}
+	g := defaults
+	r, err := s.sql.QueryContext(x, "list_opt", i)
+	if err != nil {
+		return defaults, err
+	}
+	for r.Next() {
+		if err = r.Scan(&g.enabled, &g.amount, &g.timeout, &g.remove, &g.attribution, &g.template, &g.expire, &g.edits, &g.captions, &g.language, &g.animated, &g.video, &g.zone, &g.minimum, &g.maximum, &g.silent, &g.reply); err != nil {
+			break
+		}
+	}
+	if r.Close(); err != nil {
+		return defaults, err
+	}
+	if int(g.attribution) >= len(attributions) {
+		g.attribution = attributionMessage
+	}
+	if int(g.reply) >= len(replies) {
+		g.reply = replyParent
+	}
+	if r, err = s.sql.QueryContext(x, "get_group", i); err != nil {
+		return defaults, err
+	}
+	for r.Next() {
+		if err = r.Scan(&g.deletable); err != nil {
+			break
+		}
+	}
+	if r.Close(); err != nil {
+		return defaults, err
+	}
+	if r, err = s.sql.QueryContext(x, "list_topic", i); err != nil {
+		return defaults, err
+	}
+	for r.Next() {
+		var (
+			t int
+			v override
+		)
+		if err = r.Scan(&t, &v.enabled, &v.amount, &v.timeout); err != nil {
+			break
+		}
+		if g.topics == nil {
+			g.topics = make(map[int]override)
+		}
+		g.topics[t] = v
+	}
+	if r.Close(); err != nil {
+		return defaults, err
+	}
+	if r, err = s.sql.QueryContext(x, "list_sets", i); err != nil {
+		return defaults, err
+	}
+	for r.Next() {
+		var (
+			n string
+			a bool
+		)
+		if err = r.Scan(&n, &a); err != nil {
+			break
+		}
+		if g.sets == nil {
+			g.sets = make(map[string]bool)
+		}
+		g.sets[strings.ToLower(n)], g.allowlist = a, g.allowlist || a
+	}
+	if r.Close(); err != nil {
+		return defaults, err
+	}
+	if r, err = s.sql.QueryContext(x, "list_schedule", i); err != nil {
+		return defaults, err
+	}
+	for r.Next() {
+		var w window
+		if err = r.Scan(&w.start, &w.end, &w.amount); err != nil {
+			break
+		}
+		g.schedule = append(g.schedule, w)
+	}
+	if r.Close(); err != nil {
+		return defaults, err
+	}
+	if r, err = s.sql.QueryContext(x, "list_words", i); err != nil {
+		return defaults, err
+	}
+	for r.Next() {
+		var k string
+		if err = r.Scan(&k); err != nil {
+			break
+		}
+		if g.words == nil {
+			g.words = make(map[string]struct{})
+		}
+		g.words[strings.ToLower(k)] = confirm
+	}
+	if r.Close(); err != nil {
+		return defaults, err
+	}
+	if r, err = s.sql.QueryContext(x, "list_blocks", i); err != nil {
+		return defaults, err
+	}
+	for r.Next() {
+		var b block
+		if err = r.Scan(&b.user, &b.value); err != nil {
+			break
+		}
+		if g.blocks == nil {
+			g.blocks = make(map[block]struct{})
+		}
+		g.blocks[b] = confirm
+	}
+	if r.Close(); err != nil {
+		return defaults, err
+	}
+	if r, err = s.sql.QueryContext(x, "list_optout", i); err != nil {
+		return defaults, err
+	}
+	for r.Next() {
+		var u int64
+		if err = r.Scan(&u); err != nil {
+			break
+		}
+		if g.optout == nil {
+			g.optout = make(map[int64]struct{})
+		}
+		g.optout[u] = confirm
+	}
+	if r.Close(); err != nil {
+		return defaults, err
+	}
+	s.groups.set(i, g)
+	return g, nil
+}
+func (s *Swapper) lookup(x context.Context, u int64, k string) (string, string, string, uint8, error) {
+	r, err := s.sql.QueryContext(x, "get_sticker", u, k)
+	if err != nil {
+		return "", "", "", 0, err
+	}
+	var (
+		v, i, n string
+		t       uint8
+	)
+	for r.Next() {
+		if err = r.Scan(&v, &i, &n, &t); err != nil {
+			break
+		}
+	}
+	r.Close()
+	return v, i, n, t, err
+}
+func stickerKind(v *telegram.Sticker, z bool) uint8 {
+	switch {
+	case z:
+		return stickerVideo
+	case v.IsAnimated:
+		return stickerAnimated
+	}
+	return stickerStatic
+}
+func (s *Swapper) backfill(x context.Context, u int64, k string, v *telegram.Sticker, t uint8) {
+	if _, err := s.sql.ExecContext(x, "fill_sticker", v.SetName, t, u, k); err != nil {
+		s.logger(x).Error("Received an error when attempting to backfill the sticker set (UID: %d): %s!", u, err.Error())
 		return
 	}
-	if !s.check(m.Chat.ID) {
-		s.log.Trace("Hit a timeout limit on GID %d!", m.Chat.ID)
+	s.logger(x).Trace(`Backfilled the sticker set "%s" (type %d) for swap "%s" of UID %d.`, v.SetName, t, k, u)
+}
+func (s *Swapper) record(x context.Context, i int64, n int, u int64, k, v string) {
+	if _, err := s.sql.ExecContext(x, "add_swap_log", i, n, u, k, v); err != nil {
+		s.logger(x).Error("Received an error when attempting to record a swap (GID: %d): %s!", i, err.Error())
+	}
+}
+func (s *Swapper) cleanup(x context.Context) {
+	r, err := s.sql.ExecContext(x, "clean_opt")
+	if err != nil {
+		s.logger(x).Error("Received an error when attempting to clean up group settings: %s!", err.Error())
 		return
 	}
+	if n, _ := r.RowsAffected(); n > 0 {
+		s.logger(x).Debug("Removed %d unchanged group settings entries.", n)
+	}
+	if r, err = s.sql.ExecContext(x, "clean_swap_log"); err != nil {
+		s.logger(x).Error("Received an error when attempting to clean up the swap log: %s!", err.Error())
+		return
+	}
+	if n, _ := r.RowsAffected(); n > 0 {
+		s.logger(x).Debug("Removed %d expired swap log entries.", n)
+	}
+	if r, err = s.sql.ExecContext(x, "clean_audit"); err != nil {
+		s.logger(x).Error("Received an error when attempting to clean up the settings history: %s!", err.Error())
+		return
+	}
+	if n, _ := r.RowsAffected(); n > 0 {
+		s.logger(x).Debug("Removed %d expired settings history entries.", n)
+	}
+}
+func (c *container) post(e string, p telegram.Params) (telegram.Message, error) {
+	var m telegram.Message
+	r, err := c.bot.MakeRequest(e, p)
+	if err != nil {
+		return m, err
+	}
+	err = json.Unmarshal(r.Result, &m)
+	return m, err
+}
+func (c *container) postSticker(p telegram.Params) (telegram.Message, bool, error) {
 	var (
-		v    string
-		e, p bool
-		a, t uint16
+		m telegram.Message
+		t thread
 	)
-	r, err := s.sql.QueryContext(x, "swap", m.From.ID, m.Chat.ID, strings.TrimSpace(m.Text))
+	r, err := c.bot.MakeRequest("sendSticker", p)
 	if err != nil {
-		s.log.Error("Received an error attempting to get the sticker value for GID %d, UID: %d: %s!", m.Chat.ID, m.From.ID, err.Error())
+		return m, false, err
+	}
+	if err = json.Unmarshal(r.Result, &m); err == nil {
+		err = json.Unmarshal(r.Result, &t)
+	}
+	return m, t.Sticker != nil && t.Sticker.Video, err
+}
+func (c *container) swap(x context.Context, s *Swapper, m *telegram.Message, t int, edit bool) {
+	w, z := m.Text, false
+	if len(w) == 0 {
+		w, z = m.Caption, true
+	}
+	if m.From == nil || m.From.IsBot || len(w) < keywordMin || len(w) > keywordMax || w[0] == '/' || w[0] < 33 {
 		return
 	}
-	for r.Next() {
-		if err = r.Scan(&e, &a, &t, &p, &v); err != nil {
-			break
+	if s.bans.has(m.From.ID) || s.bans.has(m.Chat.ID) {
+		return
+	}
+	k := strings.TrimSpace(w)
+	if !s.words.has(m.From.ID, k) {
+		return
+	}
+	g, err := s.group(x, m.Chat.ID)
+	if err != nil {
+		s.logger(x).Error("Received an error attempting to get the group settings for GID %d: %s!", m.Chat.ID, err.Error())
+		return
+	}
+	if (z && !g.captions) || (edit && !g.edits) {
+		return
+	}
+	if len(k) < int(g.minimum) || len(k) > int(g.maximum) {
+		return
+	}
+	if _, ok := g.words[strings.ToLower(k)]; ok {
+		return
+	}
+	if _, ok := g.optout[m.From.ID]; ok {
+		return
+	}
+	e, a, d, l := g.topic(m.Chat.ID, t)
+	if !e {
+		return
+	}
+	if w, ok := s.quiet(m.Chat.ID); ok {
+		if !w.amount.Valid {
+			s.logger(x).Trace("Swapping is paused by schedule %s in GID %d!", w.String(), m.Chat.ID)
+			return
+		}
+		if a == 0 || uint16(w.amount.Int32) < a {
+			a = uint16(w.amount.Int32)
+		}
+		if d == 0 {
+			d = 60
 		}
 	}
-	if r.Close(); err != nil {
-		s.log.Error("Received an error attempting to scan the sticker value for GID %d, UID: %d: %s!", m.Chat.ID, m.From.ID, err.Error())
+	v, f, y, q, err := s.lookup(x, m.From.ID, k)
+	if err != nil {
+		s.logger(x).Error("Received an error attempting to get the sticker value for GID %d, UID: %d: %s!", m.Chat.ID, m.From.ID, err.Error())
+		return
+	}
+	if len(v) == 0 {
+		return
+	}
+	if g.blocked(m.From.ID, k, f) {
+		s.logger(x).Trace(`Swap "%s" by "%s" is blocked in GID %d!`, k, m.From.String(), m.Chat.ID)
 		return
 	}
-	if s.update(m.Chat.ID, a, t); !e || len(v) == 0 {
+	if !g.permits(y, q) {
+		s.logger(x).Trace(`Sticker set "%s" (type %d) is not permitted in GID %d!`, y, q, m.Chat.ID)
 		return
 	}
-	s.log.Trace(`Found a swap match "%s" by "%s"!`, v, m.From.String())
-	n := telegram.NewSticker(m.Chat.ID, telegram.FileID(v))
-	if m.ReplyToMessage != nil {
-		n.ReplyToMessageID = m.ReplyToMessage.MessageID
+	if !s.check(l, a, d) {
+		s.logger(x).Trace("Hit a timeout limit on GID %d (topic %d)!", m.Chat.ID, l.thread)
+		return
 	}
-	if p {
-		s.log.Trace("Attempting to delete the swapped message %d..", m.MessageID)
+	s.logger(x).Trace(`Found a swap match "%s" by "%s"!`, v, m.From.String())
+	n := s.groupLanguage(x, g, m.From)
+	var (
+		u = &undo{name: m.From.String(), text: w, user: m.From.ID, thread: t}
+		p = threadParams(m.Chat.ID, t)
+	)
+	if p["sticker"] = v; m.ReplyToMessage != nil && m.ReplyToMessage.MessageID != t {
+		u.reply = m.ReplyToMessage.MessageID
+		p.AddNonZero("reply_to_message_id", u.reply)
+	}
+	// Captioned media is never deleted, as that would also remove the media.
+	if g.remove && g.deletable && !z {
+		s.logger(x).Trace("Attempting to delete the swapped message %d..", m.MessageID)
 		if _, err = c.bot.Request(telegram.NewDeleteMessage(m.Chat.ID, m.MessageID)); err != nil {
-			s.log.Warning("Received an error attempting to delete a message from GID %s: %s", m.Chat.ID, err.Error())
+			s.logger(x).Warning("Received an error attempting to delete a message from GID %d: %s", m.Chat.ID, err.Error())
+		} else {
+			u.removed = true
 		}
 	}
-	if v = "@" + m.From.UserName; len(v) <= 1 {
-		v = m.From.String()
+	if g.reply == replyOriginal && !u.removed {
+		p.AddNonZero("reply_to_message_id", m.MessageID)
+	}
+	if p.AddBool("disable_notification", g.silent); g.attribution == attributionNone {
+		p.AddInterface("reply_markup", undoMarkup(s.lang.get(n, "undo")))
+	}
+	r, h, err := c.postSticker(p)
+	if err != nil {
+		s.logger(x).Error("Error sending Telegram sticker to GID %d: %s!", m.Chat.ID, err.Error())
+		return
+	}
+	if q == stickerUnknown && r.Sticker != nil {
+		q = stickerKind(r.Sticker, h)
+		ok := g.permits(r.Sticker.SetName, q)
+		if s.backfill(x, m.From.ID, k, r.Sticker, q); !ok {
+			s.logger(x).Trace(`Sticker set "%s" (type %d) is not permitted in GID %d, removing the sent sticker!`, r.Sticker.SetName, q, m.Chat.ID)
+			if _, err = c.bot.Request(telegram.NewDeleteMessage(m.Chat.ID, r.MessageID)); err != nil {
+				s.logger(x).Warning("Received an error attempting to delete a message from GID %d: %s", m.Chat.ID, err.Error())
+			}
+			return
+		}
+	}
+	u.messages = append(u.messages, r.MessageID)
+	if s.record(x, m.Chat.ID, r.MessageID, m.From.ID, k, f); g.attribution == attributionNone {
+		c.track(s, m.Chat.ID, r.MessageID, u)
+		return
+	}
+	p = threadParams(m.Chat.ID, t)
+	p.AddBool("disable_notification", g.silent)
+	p.AddInterface("reply_markup", undoMarkup(s.lang.get(n, "undo")))
+	if p["text"], p["parse_mode"] = g.render(s.lang.get(n, "attribution"), m.From, k), telegram.ModeHTML; g.attribution == attributionReply {
+		p.AddNonZero("reply_to_message_id", r.MessageID)
+	}
+	if r, err = c.post("sendMessage", p); err != nil {
+		s.logger(x).Error("Error sending Telegram message to GID %d: %s!", m.Chat.ID, err.Error())
+		return
+	}
+	u.messages = append(u.messages, r.MessageID)
+	s.record(x, m.Chat.ID, r.MessageID, m.From.ID, k, f)
+	if c.track(s, m.Chat.ID, r.MessageID, u); g.expire > 0 {
+		c.expire(s, m.Chat.ID, r.MessageID, time.Duration(g.expire)*time.Second)
 	}
-	o <- n
-	o <- telegram.NewMessage(m.Chat.ID, "Swapped message from "+v)
 }
-func (c *container) receive(x context.Context, s *Swapper, g *sync.WaitGroup, o chan<- telegram.Chattable, r <-chan telegram.Update) {
-	s.log.Debug("Starting Telegram receiver thread..")
-	for g.Add(1); ; {
+func (c *container) expire(s *Swapper, i int64, n int, d time.Duration) {
+	b := c.bot
+	time.AfterFunc(d, func() {
+		if _, err := b.Request(telegram.NewDeleteMessage(i, n)); err != nil {
+			s.log.Warning("Received an error attempting to delete an expired message from GID %d: %s", i, err.Error())
+		}
+	})
+}
+func (c *container) poll(x context.Context, s *Swapper, o chan<- update) {
+	p := telegram.Params{"timeout": strconv.Itoa(int(pollTimeout / time.Second))}
+	p.AddInterface("allowed_updates", updateTypes)
+	for k := 0; ; {
 		select {
-		case n := <-r:
-			if n.InlineQuery != nil {
-				k := telegram.InlineConfig{
-					Results:       s.inline(x, n.InlineQuery),
-					CacheTime:     180,
-					IsPersonal:    true,
-					InlineQueryID: n.InlineQuery.ID,
-				}
-				if len(k.Results) == 0 {
-					k.SwitchPMParameter, k.SwitchPMText = "new", "Click here to add some Stickers!"
+		case <-x.Done():
+			return
+		default:
+		}
+		p.AddNonZero("offset", k)
+		r, err := c.bot.MakeRequest("getUpdates", p)
+		if err != nil {
+			s.logger(x).Error("Received an error when attempting to get Telegram updates, retrying in 3 seconds: %s!", err.Error())
+			select {
+			case <-time.After(time.Second * 3):
+			case <-x.Done():
+				return
+			}
+			continue
+		}
+		var v []json.RawMessage
+		if err = json.Unmarshal(r.Result, &v); err != nil {
+			s.logger(x).Error("Received an error when attempting to parse Telegram updates, retrying in 3 seconds: %s!", err.Error())
+			select {
+			case <-time.After(time.Second * 3):
+			case <-x.Done():
+				return
+			}
+			continue
+		}
+		for i := range v {
+			var (
+				n update
+				t threads
+			)
+			if err = json.Unmarshal(v[i], &n.Update); err == nil {
+				err = json.Unmarshal(v[i], &t)
+			}
+			if err != nil {
+				var e struct {
+					ID int `json:"update_id"`
 				}
-				if _, err := c.bot.Request(k); err != nil {
-					s.log.Error("Received error during inline query response: %s!", err.Error())
+				if json.Unmarshal(v[i], &e) == nil && e.ID >= k {
+					k = e.ID + 1
 				}
-				break
+				s.logger(x).Error("Received an error when attempting to parse Telegram update %d, skipping it: %s!", e.ID, err.Error())
+				continue
+			}
+			if n.UpdateID < k {
+				continue
 			}
-			if n.Message == nil || n.Message.Chat == nil || (len(n.Message.Text) == 0 && n.Message.Sticker == nil) {
-				break
+			if k = n.UpdateID + 1; t.Message != nil && t.Message.Topic {
+				n.thread = t.Message.Thread
 			}
-			if n.Message.Chat.IsPrivate() {
-				s.log.Trace("Received a possible command/sticker from %s!", n.Message.From.String())
-				s.command(x, n.Message, o)
-				break
+			if t.Message != nil && t.Message.Sticker != nil {
+				n.video = t.Message.Sticker.Video
 			}
-			if n.Message.From.IsBot {
-				break
+			if t.EditedMessage != nil && t.EditedMessage.Topic {
+				n.thread = t.EditedMessage.Thread
 			}
-			if len(n.Message.Text) > 6 && n.Message.Text[0] == '/' && stringMatchIndex(6, n.Message.Text, "/swap_") {
-				s.log.Trace("Received a possible command message from %s!", n.Message.From.String())
-				c.config(x, s, n.Message, o)
-				break
+			select {
+			case o <- n:
+			case <-x.Done():
+				return
+			}
+		}
+	}
+}
+func (c *container) receive(x context.Context, s *Swapper, g *sync.WaitGroup, o chan<- telegram.Chattable, r <-chan update) {
+	s.logger(x).Debug("Starting Telegram receiver thread with %d workers..", s.workers)
+	w := make([]chan update, s.workers)
+	for i := range w {
+		w[i] = make(chan update, 32)
+		g.Add(1)
+		go c.work(x, s, g, o, w[i])
+	}
+	for {
+		select {
+		case n := <-r:
+			if b, i := s.banned(&n.Update); b && (i == 0 || !s.evict) {
+				continue
+			}
+			select {
+			case w[updateKey(&n.Update)%uint64(len(w))] <- n:
+			case <-x.Done():
 			}
-			c.swap(x, s, n.Message, o)
 		case <-x.Done():
-			s.log.Debug("Stopping Telegram receiver thread.")
+			s.logger(x).Debug("Stopping Telegram receiver thread.")
 			g.Done()
 			return
 		}
 	}
 }
+func (c *container) work(x context.Context, s *Swapper, g *sync.WaitGroup, o chan<- telegram.Chattable, r <-chan update) {
+	for {
+		select {
+		case n := <-r:
+			var (
+				t    = time.Now()
+				k    = newFields(c, &n)
+				v, f = context.WithTimeout(context.WithValue(x, fieldsKey{}, k), s.timeout)
+			)
+			c.handle(v, s, &n, o)
+			f()
+			s.event(k, time.Since(t))
+		case <-x.Done():
+			g.Done()
+			return
+		}
+	}
+}
+func (c *container) handle(x context.Context, s *Swapper, n *update, o chan<- telegram.Chattable) {
+	if b, i := s.banned(&n.Update); b {
+		if i != 0 && s.evict {
+			c.depart(x, s, i)
+		}
+		return
+	}
+	if n.InlineQuery != nil {
+		k := telegram.InlineConfig{
+			Results:       s.inline(x, n.InlineQuery),
+			CacheTime:     180,
+			IsPersonal:    true,
+			InlineQueryID: n.InlineQuery.ID,
+		}
+		if len(k.Results) == 0 {
+			k.SwitchPMParameter, k.SwitchPMText = "new", s.lang.get(s.language(x, n.InlineQuery.From), "inline_add")
+		}
+		if _, err := c.bot.Request(k); err != nil {
+			s.logger(x).Error("Received error during inline query response: %s!", err.Error())
+		}
+		return
+	}
+	if n.CallbackQuery != nil {
+		c.callback(x, s, n.CallbackQuery)
+		return
+	}
+	if n.MyChatMember != nil {
+		c.joined(x, s, n.MyChatMember, o)
+		return
+	}
+	if n.ChatMember != nil {
+		s.admins.delete(member{chat: n.ChatMember.Chat.ID, user: n.ChatMember.NewChatMember.User.ID})
+		return
+	}
+	if n.EditedMessage != nil && n.EditedMessage.Chat != nil && !n.EditedMessage.Chat.IsPrivate() && !s.isPaused() {
+		c.swap(x, s, n.EditedMessage, n.thread, true)
+		return
+	}
+	if n.Message == nil || n.Message.Chat == nil {
+		return
+	}
+	switch m := n.Message; {
+	case m.MigrateToChatID != 0:
+		// Only the new chat's MigrateFromChatID message moves the data, as both
+		// messages can be handled at the same time by different workers.
+		return
+	case m.MigrateFromChatID != 0:
+		s.migrate(x, m.MigrateFromChatID, m.Chat.ID)
+		return
+	case m.LeftChatMember != nil && m.LeftChatMember.ID == c.bot.Self.ID:
+		s.logger(x).Debug("Removed from GID %d, removing group data..", m.Chat.ID)
+		s.leave(x, m.Chat.ID)
+		return
+	case len(m.Text) == 0 && m.Sticker == nil && (len(m.Caption) == 0 || m.Chat.IsPrivate()):
+		return
+	}
+	if len(n.Message.Text) > 1 && n.Message.Text[0] == '/' {
+		v, ok := c.strip(n.Message.Text)
+		if !ok {
+			return
+		}
+		n.Message.Text = v
+	}
+	if n.Message.Chat.IsPrivate() {
+		s.logger(x).Trace("Received a possible command/sticker from %s!", n.Message.From.String())
+		if c.owner(x, s, n.Message, o) {
+			return
+		}
+		if s.isPaused() && !s.isOwner(n.Message.From.ID) {
+			o <- telegram.NewMessage(n.Message.Chat.ID, s.lang.get(s.language(x, n.Message.From), "maintenance"))
+			return
+		}
+		c.command(x, s, n.Message, n.video, o)
+		return
+	}
+	if n.Message.From == nil || (n.Message.From.IsBot && (n.Message.SenderChat == nil || n.Message.SenderChat.ID != n.Message.Chat.ID)) {
+		return
+	}
+	if len(n.Message.Text) > 6 && n.Message.Text[0] == '/' && stringMatchIndex(6, n.Message.Text, "/swap_") {
+		s.logger(x).Trace("Received a possible command message from %s!", n.Message.From.String())
+		if s.isPaused() {
+			sendResponse(o, n.Message.Chat.ID, n.Message.MessageID, s.lang.get(s.language(x, n.Message.From), "maintenance"))
+			return
+		}
+		c.config(x, s, n.Message, n.thread, o)
+		return
+	}
+	if s.isPaused() {
+		return
+	}
+	c.swap(x, s, n.Message, n.thread, false)
+}
+func (c *container) strip(v string) (string, bool) {
+	i := strings.IndexAny(v, " \n")
+	if i == -1 {
+		i = len(v)
+	}
+	k := strings.IndexByte(v[:i], '@')
+	if k == -1 {
+		return v, true
+	}
+	if !strings.EqualFold(v[k+1:i], c.bot.Self.UserName) {
+		return v, false
+	}
+	return v[:k] + v[i:], true
+}
+func (s *Swapper) banned(n *telegram.Update) (bool, int64) {
+	if u := n.SentFrom(); u != nil && s.bans.has(u.ID) {
+		return true, 0
+	}
+	if i := int64(updateKey(n)); i < 0 && s.bans.has(i) {
+		return true, i
+	}
+	return false, 0
+}
+func (c *container) depart(x context.Context, s *Swapper, i int64) {
+	s.logger(x).Debug("Leaving banned GID %d..", i)
+	if _, err := c.bot.Request(telegram.LeaveChatConfig{ChatID: i}); err != nil {
+		s.logger(x).Debug("Received an error attempting to leave GID %d: %s", i, err.Error())
+	}
+	s.leave(x, i)
+}
+func updateKey(n *telegram.Update) uint64 {
+	switch {
+	case n.Message != nil && n.Message.Chat != nil:
+		return uint64(n.Message.Chat.ID)
+	case n.EditedMessage != nil && n.EditedMessage.Chat != nil:
+		return uint64(n.EditedMessage.Chat.ID)
+	case n.CallbackQuery != nil && n.CallbackQuery.Message != nil && n.CallbackQuery.Message.Chat != nil:
+		return uint64(n.CallbackQuery.Message.Chat.ID)
+	case n.MyChatMember != nil:
+		return uint64(n.MyChatMember.Chat.ID)
+	case n.ChatMember != nil:
+		return uint64(n.ChatMember.Chat.ID)
+	}
+	if u := n.SentFrom(); u != nil {
+		return uint64(u.ID)
+	}
+	return 0
+}
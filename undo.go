@@ -0,0 +1,109 @@
+// Copyright (C) 2021 - 2025 PurpleSec Team
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published
+// by the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+//
+
+package swapper
+
+import (
+	"context"
+	"html"
+	"strings"
+	"time"
+
+	telegram "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const undoWindow = time.Minute
+
+func undoMarkup(v string) telegram.InlineKeyboardMarkup {
+	return telegram.NewInlineKeyboardMarkup(
+		telegram.NewInlineKeyboardRow(telegram.NewInlineKeyboardButtonData(v, "undo")),
+	)
+}
+
+type undo struct {
+	name     string
+	text     string
+	messages []int
+	user     int64
+	thread   int
+	reply    int
+	removed  bool
+}
+type message struct {
+	chat int64
+	id   int
+}
+
+func (c *container) track(s *Swapper, i int64, n int, u *undo) {
+	k := message{chat: i, id: n}
+	s.undos.set(k, u)
+	b := c.bot
+	time.AfterFunc(undoWindow, func() {
+		if !s.undos.take(k) {
+			return
+		}
+		e := telegram.NewEditMessageReplyMarkup(i, n, telegram.InlineKeyboardMarkup{InlineKeyboard: [][]telegram.InlineKeyboardButton{}})
+		if _, err := b.Request(e); err != nil {
+			s.log.Debug("Received an error attempting to remove the undo button from GID %d: %s", i, err.Error())
+		}
+	})
+}
+func (c *container) callback(x context.Context, s *Swapper, q *telegram.CallbackQuery) {
+	if s.isPaused() {
+		c.answer(s, q.ID, s.lang.get(s.language(x, q.From), "maintenance"))
+		return
+	}
+	if q.Message != nil && q.Message.Chat != nil && strings.HasPrefix(q.Data, panelPrefix) {
+		c.configure(x, s, q)
+		return
+	}
+	if q.Message == nil || q.Message.Chat == nil || q.Data != "undo" {
+		c.answer(s, q.ID, "")
+		return
+	}
+	k := message{chat: q.Message.Chat.ID, id: q.Message.MessageID}
+	u, ok := s.undos.get(k)
+	if !ok {
+		c.answer(s, q.ID, s.lang.get(s.language(x, q.From), "undo_expired"))
+		return
+	}
+	if u.user != q.From.ID {
+		c.answer(s, q.ID, s.lang.get(s.language(x, q.From), "undo_denied"))
+		return
+	}
+	s.undos.delete(k)
+	c.answer(s, q.ID, "")
+	s.logger(x).Trace("User %s undid a swap in GID %d.", q.From.String(), k.chat)
+	for _, n := range u.messages {
+		if _, err := c.bot.Request(telegram.NewDeleteMessage(k.chat, n)); err != nil {
+			s.logger(x).Warning("Received an error attempting to delete a swap message from GID %d: %s", k.chat, err.Error())
+		}
+	}
+	if !u.removed {
+		return
+	}
+	p := threadParams(k.chat, u.thread)
+	p["text"], p["parse_mode"] = "<b>"+html.EscapeString(u.name)+"</b>:\n<blockquote>"+html.EscapeString(u.text)+"</blockquote>", telegram.ModeHTML
+	p.AddNonZero("reply_to_message_id", u.reply)
+	if _, err := c.post("sendMessage", p); err != nil {
+		s.logger(x).Error("Error sending Telegram message to GID %d: %s!", k.chat, err.Error())
+	}
+}
+func (c *container) answer(s *Swapper, i, v string) {
+	if _, err := c.bot.Request(telegram.NewCallbackWithAlert(i, v)); err != nil {
+		s.log.Debug("Received an error attempting to answer a callback query: %s", err.Error())
+	}
+}